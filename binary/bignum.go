@@ -0,0 +1,112 @@
+package binary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// The CBOR library cannot decode a major type 1 (negative) integer
+// below math.MinInt64 into an interface{}, although such integers
+// are the standard encoding of Integer literals between -2^64 and
+// -2^63-1.  widenNegativeInts rewrites a CBOR item so that every such
+// integer is instead encoded as a negative bignum (tag 3) with the
+// same value, which the library decodes as a cbor.Tag.
+
+var errTruncatedCbor = errors.New("CBOR decode error: unexpected end of input")
+
+func widenNegativeInts(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	if _, err := widenItem(data, &out, 0); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// widenItem copies the first CBOR item in data to out, rewriting
+// large negative integers, and returns the remaining input.
+func widenItem(data []byte, out *bytes.Buffer, depth int) ([]byte, error) {
+	if depth > 64 {
+		return nil, errors.New("CBOR decode error: exceeded max nested level")
+	}
+	if len(data) == 0 {
+		return nil, errTruncatedCbor
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	var arg uint64
+	headLen := 1
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		n := 1 << (info - 24)
+		if len(data) < 1+n {
+			return nil, errTruncatedCbor
+		}
+		for _, b := range data[1 : 1+n] {
+			arg = arg<<8 | uint64(b)
+		}
+		headLen += n
+	case info == 31 && major >= 2 && major <= 5:
+		// indefinite length: copy items until the break code
+		out.WriteByte(data[0])
+		data = data[1:]
+		for {
+			if len(data) == 0 {
+				return nil, errTruncatedCbor
+			}
+			if data[0] == 0xff {
+				out.WriteByte(0xff)
+				return data[1:], nil
+			}
+			var err error
+			data, err = widenItem(data, out, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New("CBOR decode error: invalid additional information")
+	}
+	head, rest := data[:headLen], data[headLen:]
+	switch major {
+	case 1:
+		if arg > math.MaxInt64 {
+			// tag 3 wrapping an 8-byte byte string; arg has its
+			// top bit set so it needs all 8 bytes
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], arg)
+			out.Write([]byte{0xc3, 0x48})
+			out.Write(b[:])
+			return rest, nil
+		}
+		out.Write(head)
+		return rest, nil
+	case 2, 3:
+		if uint64(len(rest)) < arg {
+			return nil, errTruncatedCbor
+		}
+		out.Write(head)
+		out.Write(rest[:arg])
+		return rest[arg:], nil
+	case 4, 5, 6:
+		out.Write(head)
+		items := arg
+		if major == 5 {
+			items *= 2
+		} else if major == 6 {
+			items = 1
+		}
+		for i := uint64(0); i < items; i++ {
+			var err error
+			rest, err = widenItem(rest, out, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rest, nil
+	}
+	out.Write(head)
+	return rest, nil
+}
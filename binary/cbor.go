@@ -1,11 +1,17 @@
 package binary
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math/big"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	. "github.com/wallyqs/dhall.go/term"
@@ -24,6 +30,10 @@ var nameToBuiltin = map[string]Term{
 	"List":     List,
 	"Optional": Optional,
 	"None":     None,
+	"Date":     Date,
+	"Time":     Time,
+	"TimeZone": TimeZone,
+	"Bytes":    Bytes,
 
 	"Natural/build":     NaturalBuild,
 	"Natural/fold":      NaturalFold,
@@ -44,6 +54,12 @@ var nameToBuiltin = map[string]Term{
 	"Text/show":    TextShow,
 	"Text/replace": TextReplace,
 
+	"Date/show":     DateShow,
+	"Time/show":     TimeShow,
+	"TimeZone/show": TimeZoneShow,
+
+	"Bytes/show": BytesShow,
+
 	"List/build":   ListBuild,
 	"List/fold":    ListFold,
 	"List/length":  ListLength,
@@ -70,6 +86,31 @@ func unwrapInt(i interface{}) (int, error) {
 	return 0, fmt.Errorf("couldn't interpret %v as int", i)
 }
 
+// unwrapBigInt interprets i as an integer of arbitrary size, which
+// may be a plain CBOR integer or a bignum (tag 2 or 3).
+func unwrapBigInt(i interface{}) (*big.Int, error) {
+	switch val := i.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(val), nil
+	case int64:
+		return big.NewInt(val), nil
+	case cbor.Tag:
+		b, ok := val.Content.([]byte)
+		if !ok {
+			break
+		}
+		switch val.Number {
+		case 2:
+			return new(big.Int).SetBytes(b), nil
+		case 3:
+			// a negative bignum n encodes the value -1-n
+			n := new(big.Int).SetBytes(b)
+			return n.Not(n), nil
+		}
+	}
+	return nil, fmt.Errorf("couldn't interpret %v as an integer", i)
+}
+
 func unwrapString(i interface{}) (string, error) {
 	if val, ok := i.(string); ok {
 		return val, nil
@@ -77,6 +118,50 @@ func unwrapString(i interface{}) (string, error) {
 	return "", fmt.Errorf("couldn't interpret %v as string", i)
 }
 
+// daysIn returns the number of days in the given month, taking leap
+// years into account, as the parser does for date literals.
+func daysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// decodeSeconds decodes the seconds field of a time literal, which
+// is a CBOR decimal fraction (tag 4), into whole seconds and the
+// digits after the decimal point.
+func decodeSeconds(i interface{}) (int, string, error) {
+	tag, ok := i.(cbor.Tag)
+	if !ok || tag.Number != 4 {
+		return 0, "", fmt.Errorf("couldn't interpret %v as a decimal fraction", i)
+	}
+	content, ok := tag.Content.([]interface{})
+	if !ok || len(content) != 2 {
+		return 0, "", fmt.Errorf("couldn't interpret %v as a decimal fraction", i)
+	}
+	exponent, err := unwrapInt(content[0])
+	if err != nil {
+		return 0, "", err
+	}
+	if exponent > 0 {
+		return 0, "", fmt.Errorf("CBOR decode error: positive exponent %d in time literal", exponent)
+	}
+	mantissa, err := unwrapBigInt(content[1])
+	if err != nil {
+		return 0, "", err
+	}
+	if mantissa.Sign() < 0 {
+		return 0, "", fmt.Errorf("couldn't interpret %v as a natural number", content[1])
+	}
+	precision := -exponent
+	digits := mantissa.String()
+	if len(digits) <= precision {
+		digits = strings.Repeat("0", precision-len(digits)+1) + digits
+	}
+	second, err := strconv.Atoi(digits[:len(digits)-precision])
+	if err != nil {
+		return 0, "", err
+	}
+	return second, digits[len(digits)-precision:], nil
+}
+
 func decodeMap(i interface{}) (map[string]Term, error) {
 	if val, ok := i.(map[interface{}]interface{}); ok {
 		decodedM := make(map[string]Term, len(val))
@@ -328,17 +413,20 @@ func decode(decodedCbor interface{}) (Term, error) {
 				}
 				return If{Cond: cond, T: tBranch, F: fBranch}, nil
 			case 15: // natural literal
-				n, err := unwrapUint(val[1])
+				n, err := unwrapBigInt(val[1])
 				if err != nil {
 					return nil, err
 				}
-				return NaturalLit(n), nil
+				if n.Sign() < 0 {
+					return nil, fmt.Errorf("CBOR decode error: negative natural literal %v", n)
+				}
+				return NewNaturalLit(n), nil
 			case 16: // integer literal
-				n, err := unwrapInt(val[1])
+				n, err := unwrapBigInt(val[1])
 				if err != nil {
 					return nil, err
 				}
-				return IntegerLit(n), nil
+				return NewIntegerLit(n), nil
 			case 18: // text literal
 				i := 1
 				var chunks Chunks
@@ -365,6 +453,13 @@ func decode(decodedCbor interface{}) (Term, error) {
 				}
 				return Assert{Annotation: annot}, nil
 			case 24: // imports
+				mode, err := unwrapUint(val[2])
+				if err != nil {
+					return nil, err
+				}
+				if mode > uint(RawBytes) {
+					return nil, fmt.Errorf("CBOR decode error: unknown import mode %d", mode)
+				}
 				importLabel, err := unwrapInt(val[3])
 				if err != nil {
 					return nil, err
@@ -401,7 +496,15 @@ func decode(decodedCbor interface{}) (Term, error) {
 					if err != nil {
 						return nil, err
 					}
-					f = NewRemoteFile(u)
+					if val[4] == nil {
+						f = NewRemoteFile(u)
+						break
+					}
+					headers, err := decode(val[4])
+					if err != nil {
+						return nil, err
+					}
+					f = NewRemoteFileUsing(u, headers)
 				case 2, 3, 4, 5:
 					var file string
 					if importLabel == 2 {
@@ -432,7 +535,7 @@ func decode(decodedCbor interface{}) (Term, error) {
 				default:
 					return nil, fmt.Errorf("CBOR decode error: couldn't decode %#v", val)
 				}
-				return Import{ImportHashed: ImportHashed{Fetchable: f}}, nil
+				return Import{ImportHashed: ImportHashed{Fetchable: f}, ImportMode: ImportMode(mode)}, nil
 			case 25: // let
 				if len(val)%3 != 2 {
 					return nil, fmt.Errorf("CBOR decode error: unexpected array length %d when decoding let", len(val))
@@ -505,6 +608,107 @@ func decode(decodedCbor interface{}) (Term, error) {
 					return nil, err
 				}
 				return With{r, path, v}, nil
+			case 30: // date literal
+				if len(val) != 4 {
+					return nil, fmt.Errorf("CBOR decode error: malformed date literal: %v", val)
+				}
+				// the years which a date literal can be written with
+				// are 0 to 9999
+				var fields [3]int
+				for i, max := range [3]uint{9999, 12, 31} {
+					n, err := unwrapUint(val[i+1])
+					if err != nil {
+						return nil, err
+					}
+					if n > max {
+						return nil, fmt.Errorf("CBOR decode error: invalid %s %d in date literal", [3]string{"year", "month", "day"}[i], n)
+					}
+					fields[i] = int(n)
+				}
+				year, month, day := fields[0], fields[1], fields[2]
+				if month < 1 {
+					return nil, fmt.Errorf("CBOR decode error: invalid month %d in date literal", month)
+				}
+				if day < 1 || day > daysIn(year, month) {
+					return nil, fmt.Errorf("CBOR decode error: invalid day %d in date literal", day)
+				}
+				return DateLit{Year: year, Month: month, Day: day}, nil
+			case 31: // time literal
+				if len(val) != 4 {
+					return nil, fmt.Errorf("CBOR decode error: malformed time literal: %v", val)
+				}
+				hour, err := unwrapUint(val[1])
+				if err != nil {
+					return nil, err
+				}
+				minute, err := unwrapUint(val[2])
+				if err != nil {
+					return nil, err
+				}
+				second, fraction, err := decodeSeconds(val[3])
+				if err != nil {
+					return nil, err
+				}
+				if hour > 23 {
+					return nil, fmt.Errorf("CBOR decode error: invalid hour %d in time literal", hour)
+				}
+				if minute > 59 {
+					return nil, fmt.Errorf("CBOR decode error: invalid minute %d in time literal", minute)
+				}
+				if second > 59 {
+					return nil, fmt.Errorf("CBOR decode error: invalid second %d in time literal", second)
+				}
+				return TimeLit{
+					Hour:     int(hour),
+					Minute:   int(minute),
+					Second:   second,
+					Fraction: fraction,
+				}, nil
+			case 32: // time zone literal
+				if len(val) != 4 {
+					return nil, fmt.Errorf("CBOR decode error: malformed time zone literal: %v", val)
+				}
+				positive, ok := val[1].(bool)
+				if !ok {
+					return nil, fmt.Errorf("couldn't interpret %v as bool", val[1])
+				}
+				hours, err := unwrapUint(val[2])
+				if err != nil {
+					return nil, err
+				}
+				minutes, err := unwrapUint(val[3])
+				if err != nil {
+					return nil, err
+				}
+				if hours > 23 {
+					return nil, fmt.Errorf("CBOR decode error: invalid hour %d in time zone literal", hours)
+				}
+				if minutes > 59 {
+					return nil, fmt.Errorf("CBOR decode error: invalid minute %d in time zone literal", minutes)
+				}
+				offset := int(hours*60 + minutes)
+				if !positive {
+					offset = -offset
+				}
+				return TimeZoneLit(offset), nil
+			case 33: // bytes literal
+				if len(val) != 2 {
+					return nil, fmt.Errorf("CBOR decode error: malformed bytes literal: %v", val)
+				}
+				b, ok := val[1].([]byte)
+				if !ok {
+					return nil, fmt.Errorf("couldn't interpret %v as []byte", val[1])
+				}
+				return BytesLit(b), nil
+			case 34: // showConstructor
+				if len(val) != 2 {
+					return nil, fmt.Errorf("CBOR decode error: malformed showConstructor expression: %v", val)
+				}
+				expr, err := decode(val[1])
+				if err != nil {
+					return nil, err
+				}
+				return ShowConstructor{Expr: expr}, nil
 			}
 		}
 	}
@@ -517,18 +721,30 @@ func EncodeAsCbor(w io.Writer, e Term) error {
 	if err != nil {
 		return err
 	}
-	return em.NewEncoder(w).Encode(e)
+	return em.NewEncoder(w).Encode(StripNotes(e))
 }
 
 // DecodeAsCbor decodes CBOR from the io.Reader and returns the resulting Expr
 func DecodeAsCbor(r io.Reader) (Term, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 	var b interface{}
 	dm, err := cbor.DecOptions{MaxNestedLevels: 64}.DecMode()
 	if err != nil {
 		return nil, err
 	}
-	dec := dm.NewDecoder(r)
-	err = dec.Decode(&b)
+	err = dm.NewDecoder(bytes.NewReader(data)).Decode(&b)
+	var typeErr *cbor.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// possibly an Integer literal below math.MinInt64
+		data, err = widenNegativeInts(data)
+		if err != nil {
+			return nil, err
+		}
+		err = dm.NewDecoder(bytes.NewReader(data)).Decode(&b)
+	}
 	if err != nil {
 		return nil, err
 	}
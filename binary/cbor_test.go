@@ -0,0 +1,60 @@
+package binary_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/wallyqs/dhall.go/binary"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// seconds encodes whole seconds as a CBOR decimal fraction.
+func seconds(s int) cbor.Tag {
+	return cbor.Tag{Number: 4, Content: []interface{}{0, s}}
+}
+
+func TestDecodeTemporalLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoded  []interface{}
+		expected term.Term
+		err      string
+	}{
+		{"date", []interface{}{30, 2020, 2, 29}, term.DateLit{Year: 2020, Month: 2, Day: 29}, ""},
+		{"year too large", []interface{}{30, 10000, 1, 1}, nil, "invalid year 10000"},
+		{"huge year", []interface{}{30, uint64(1) << 63, 1, 1}, nil, "invalid year"},
+		{"bad month", []interface{}{30, 2021, 13, 1}, nil, "invalid month 13"},
+		{"month 0", []interface{}{30, 2021, 0, 1}, nil, "invalid month 0"},
+		{"Feb 29 in a non-leap year", []interface{}{30, 2021, 2, 29}, nil, "invalid day 29"},
+		{"time", []interface{}{31, 23, 59, seconds(59)}, term.TimeLit{Hour: 23, Minute: 59, Second: 59}, ""},
+		{"hour 24", []interface{}{31, 24, 0, seconds(0)}, nil, "invalid hour 24"},
+		{"minute 60", []interface{}{31, 23, 60, seconds(0)}, nil, "invalid minute 60"},
+		{"second 60", []interface{}{31, 23, 59, seconds(60)}, nil, "invalid second 60"},
+		{"time zone", []interface{}{32, false, 23, 59}, term.TimeZoneLit(-23*60 - 59), ""},
+		{"zone hour 24", []interface{}{32, true, 24, 0}, nil, "invalid hour 24"},
+		{"zone minute 60", []interface{}{32, true, 0, 60}, nil, "invalid minute 60"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := cbor.Marshal(test.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := binary.DecodeAsCbor(bytes.NewReader(encoded))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v, %v", test.err, actual, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Fatalf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wallyqs/dhall.go/imports"
+)
+
+const cacheHelpText = `usage: dhall-go cache [--dir dir] list
+       dhall-go cache [--dir dir] verify [--remove]
+       dhall-go cache [--dir dir] prune [--max-age age] [--max-size size]
+
+Manages the cache of imported expressions.
+
+  list    List the entries, with their size and when they were last used
+  verify  List the entries whose contents don't match their hash, and
+          with --remove, remove them
+  prune   Remove the entries last used longer ago than --max-age, such
+          as 720h, and then the least recently used entries until the
+          cache is at most --max-size, such as 500M
+
+`
+
+// runCache runs `dhall-go cache` with args, and returns the exit
+// status.
+func runCache(args []string) int {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, cacheHelpText)
+		fs.PrintDefaults()
+	}
+	var dir string
+	fs.StringVar(&dir, "dir", "", "The cache directory (default: the standard Dhall cache directory)")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	if dir == "" {
+		var err error
+		dir, err = imports.DhallCacheDir()
+		if err != nil {
+			printError(err, false)
+			return 1
+		}
+	}
+	cache := imports.NewLocalCache(dir)
+
+	var err error
+	switch fs.Arg(0) {
+	case "list":
+		err = listCache(cache)
+	case "verify":
+		var remove bool
+		verifyFlags := flag.NewFlagSet("cache verify", flag.ExitOnError)
+		verifyFlags.BoolVar(&remove, "remove", false, "Remove the corrupt entries")
+		verifyFlags.Parse(fs.Args()[1:])
+		err = verifyCache(cache, remove)
+	case "prune":
+		var maxAge time.Duration
+		var maxSize string
+		pruneFlags := flag.NewFlagSet("cache prune", flag.ExitOnError)
+		pruneFlags.DurationVar(&maxAge, "max-age", 0, "Remove the entries last used longer ago than this")
+		pruneFlags.StringVar(&maxSize, "max-size", "", "Remove the least recently used entries until the cache is at most this size, in bytes or with a K, M or G suffix")
+		pruneFlags.Parse(fs.Args()[1:])
+		err = pruneCache(cache, maxAge, maxSize)
+	default:
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		printError(err, false)
+		return 1
+	}
+	return 0
+}
+
+func entryName(entry imports.CacheEntry) string {
+	// the hash is a multihash; skip its sha256 prefix
+	return "sha256:" + hex.EncodeToString(entry.Hash[2:])
+}
+
+func listCache(cache imports.LocalCache) error {
+	entries, err := cache.Entries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fmt.Printf("%s\t%d\t%s\n", entryName(entry), entry.Size, entry.LastUsed.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func verifyCache(cache imports.LocalCache, remove bool) error {
+	corrupt, err := cache.Verify()
+	if err != nil {
+		return err
+	}
+	for _, entry := range corrupt {
+		if !remove {
+			fmt.Printf("corrupt: %s\n", entryName(entry))
+			continue
+		}
+		if err := cache.Remove(entry.Hash); err != nil {
+			return err
+		}
+		fmt.Printf("removed: %s\n", entryName(entry))
+	}
+	if len(corrupt) > 0 && !remove {
+		return fmt.Errorf("%d corrupt cache entries", len(corrupt))
+	}
+	return nil
+}
+
+func pruneCache(cache imports.LocalCache, maxAge time.Duration, maxSize string) error {
+	if maxAge == 0 && maxSize == "" {
+		return errors.New("prune needs --max-age or --max-size")
+	}
+	var size int64
+	if maxSize != "" {
+		var err error
+		size, err = parseSize(maxSize)
+		if err != nil {
+			return err
+		}
+	}
+	removed, err := cache.Prune(maxAge, size)
+	for _, entry := range removed {
+		fmt.Printf("removed: %s\n", entryName(entry))
+	}
+	return err
+}
+
+// parseSize parses a size in bytes, optionally with a K, M or G
+// suffix for kibibytes, mebibytes or gibibytes.
+func parseSize(size string) (int64, error) {
+	s := size
+	multiplier := int64(1)
+	for i, suffix := range []string{"K", "M", "G"} {
+		if strings.HasSuffix(strings.ToUpper(size), suffix) {
+			multiplier = 1 << (10 * (i + 1))
+			s = s[:len(s)-1]
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
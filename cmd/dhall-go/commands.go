@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wallyqs/dhall.go/binary"
+	"github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/imports"
+	"github.com/wallyqs/dhall.go/parser"
+	"github.com/wallyqs/dhall.go/printer"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// A command is a dhall-go subcommand.  run is called with the
+// arguments after the command name, and returns the exit status.
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	// set in init, since the commands look themselves up
+	commands = []command{
+		{"cache", "List, verify and prune the cache of imported expressions", runCache},
+		{"format", "Format Dhall source, keeping comments", runFormat},
+		{"freeze", "Pin imports with their semantic hashes", runFreeze},
+		{"hash", "Print the semantic hash of a Dhall expression", runHash},
+		{"normalize", "Print the normal form of a Dhall expression", runNormalize},
+		{"resolve", "Resolve the imports of a Dhall expression, or list them", runResolve},
+		{"type", "Print the type of a Dhall expression", runType},
+		{"vendor", "Store the remote imports of Dhall files for offline use", runVendor},
+	}
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// inputFlags are the flags of a command which reads a Dhall
+// expression from a file or standard input.
+type inputFlags struct {
+	file         string
+	explain      bool
+	vendor       string
+	strictVendor bool
+}
+
+// parseInputFlags parses args for the command name, which does what
+// description says.
+func parseInputFlags(name, description string, args []string) *inputFlags {
+	in := &inputFlags{}
+	in.flagSet(name, description).Parse(args)
+	return in
+}
+
+// flagSet returns a FlagSet with in's flags for the command name,
+// which does what description says.
+func (in *inputFlags) flagSet(name, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: dhall-go %s [-f file]\n\n", name)
+		fmt.Fprintf(os.Stderr, "%s, read from file or standard input.\n\n", description)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&in.file, "f", "", "Read the expression from file")
+	fs.StringVar(&in.file, "file", "", "Read the expression from file")
+	fs.BoolVar(&in.explain, "explain", false, "Explain type errors in detail")
+	addVendorFlags(fs, &in.vendor, &in.strictVendor)
+	return fs
+}
+
+// parse parses the input, and returns it with the ancestors onto
+// which its imports are chained: relative imports in a file are
+// resolved relative to the file.
+func (in *inputFlags) parse() (term.Term, []term.Fetchable, error) {
+	if in.file == "" {
+		expr, err := parser.ParseReader("(stdin)", os.Stdin, parser.WithSpans())
+		return expr, nil, err
+	}
+	expr, err := parser.ParseFile(in.file, parser.WithSpans())
+	return expr, []term.Fetchable{term.LocalFile(in.file)}, err
+}
+
+// loader returns the Loader to resolve the input's imports with.
+func (in *inputFlags) loader() (*imports.Loader, error) {
+	return newLoader(in.vendor, in.strictVendor)
+}
+
+// addVendorFlags adds the --vendor and --vendor-strict flags to fs.
+func addVendorFlags(fs *flag.FlagSet, vendor *string, strictVendor *bool) {
+	fs.StringVar(vendor, "vendor", "", "Fetch remote imports from this vendor directory when they are vendored there")
+	fs.BoolVar(strictVendor, "vendor-strict", false, "Fetch remote imports only from the --vendor directory, never from the network")
+}
+
+// newLoader returns a Loader which fetches remote imports from the
+// vendor directory, if any, and only from there if strictVendor is
+// set.
+func newLoader(vendor string, strictVendor bool) (*imports.Loader, error) {
+	if vendor == "" {
+		if strictVendor {
+			return nil, errors.New("--vendor-strict needs --vendor")
+		}
+		return imports.NewLoader(), nil
+	}
+	v, err := imports.OpenVendor(vendor)
+	if err != nil {
+		return nil, err
+	}
+	if strictVendor {
+		return imports.NewLoader(imports.WithStrictVendor(v)), nil
+	}
+	return imports.NewLoader(imports.WithVendor(v)), nil
+}
+
+// load parses the input, resolves its imports and typechecks it.
+func (in *inputFlags) load() (term.Term, core.Value, error) {
+	expr, ancestors, err := in.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	loader, err := in.loader()
+	if err != nil {
+		return nil, nil, err
+	}
+	resolved, err := loader.Load(expr, ancestors...)
+	if err != nil {
+		return nil, nil, err
+	}
+	typ, err := core.TypeOf(resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolved, typ, nil
+}
+
+// fail prints err, and returns the exit status for it.
+func (in *inputFlags) fail(err error) int {
+	printError(err, in.explain)
+	return 1
+}
+
+// printError prints err to standard error, with the long-form
+// explanation of a type error if explain is set.
+func printError(err error, explain bool) {
+	var typeErr *core.TypeError
+	if explain && errors.As(err, &typeErr) {
+		fmt.Fprintf(os.Stderr, "dhall-go: %s\n", typeErr.Explain())
+		return
+	}
+	fmt.Fprintf(os.Stderr, "dhall-go: %s\n", err)
+}
+
+// printTerm prints t as Dhall source.
+func printTerm(t term.Term) error {
+	s, err := printer.Sprint(t)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s)
+	return nil
+}
+
+func runNormalize(args []string) int {
+	in := parseInputFlags("normalize", findCommand("normalize").description, args)
+	expr, _, err := in.load()
+	if err != nil {
+		return in.fail(err)
+	}
+	if err := printTerm(core.Quote(core.Eval(expr))); err != nil {
+		return in.fail(err)
+	}
+	return 0
+}
+
+func runType(args []string) int {
+	in := parseInputFlags("type", findCommand("type").description, args)
+	_, typ, err := in.load()
+	if err != nil {
+		return in.fail(err)
+	}
+	if err := printTerm(core.Quote(typ)); err != nil {
+		return in.fail(err)
+	}
+	return 0
+}
+
+func runHash(args []string) int {
+	in := parseInputFlags("hash", findCommand("hash").description, args)
+	expr, _, err := in.load()
+	if err != nil {
+		return in.fail(err)
+	}
+	hash, err := binary.SemanticHash(core.Eval(expr))
+	if err != nil {
+		return in.fail(err)
+	}
+	// the hash is a multihash; skip its sha256 prefix
+	fmt.Printf("sha256:%s\n", hex.EncodeToString(hash[2:]))
+	return 0
+}
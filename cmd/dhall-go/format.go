@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/wallyqs/dhall.go/parser"
+	"github.com/wallyqs/dhall.go/printer"
+)
+
+const formatHelpText = `usage: dhall-go format [--check] [file ...]
+
+Formats Dhall source, keeping its comments.  With no files, formats
+standard input to standard output; otherwise rewrites each file in
+place.  With --check, lists the files which are not formatted instead,
+and exits with status 1 if there are any.
+`
+
+// runFormat runs `dhall-go format` with args, and returns the exit
+// status.
+func runFormat(args []string) int {
+	fs := flag.NewFlagSet("format", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, formatHelpText)
+		fs.PrintDefaults()
+	}
+	var check bool
+	fs.BoolVar(&check, "check", false, "Check that the input is formatted, without changing it")
+	fs.Parse(args)
+	return rewrite(fs.Args(), check, format)
+}
+
+// format returns src formatted, with its comments.
+func format(filename string, src []byte) ([]byte, error) {
+	expr, err := parser.Parse(filename, src, parser.WithComments())
+	if err != nil {
+		return nil, err
+	}
+	out, err := printer.Sprint(expr)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out + "\n"), nil
+}
+
+// rewrite applies f to the source in each of files, rewriting them in
+// place, or to standard input, writing to standard output, if there
+// are no files.  With check, it lists the inputs which f would change
+// instead.  It returns the exit status.
+func rewrite(files []string, check bool, f func(filename string, src []byte) ([]byte, error)) int {
+	if len(files) == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			printError(err, false)
+			return 1
+		}
+		out, err := f("(stdin)", src)
+		if err != nil {
+			printError(err, false)
+			return 1
+		}
+		if check {
+			if !bytes.Equal(src, out) {
+				fmt.Println("(stdin)")
+				return 1
+			}
+			return 0
+		}
+		os.Stdout.Write(out)
+		return 0
+	}
+
+	status := 0
+	for _, file := range files {
+		changed, err := rewriteFile(file, !check, f)
+		if err != nil {
+			printError(err, false)
+			status = 1
+			continue
+		}
+		if changed && check {
+			fmt.Println(file)
+			status = 1
+		}
+	}
+	return status
+}
+
+// rewriteFile applies f to file, rewriting it if write is set, and
+// reports whether f changed it.
+func rewriteFile(file string, write bool, f func(filename string, src []byte) ([]byte, error)) (bool, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	out, err := f(file, src)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(src, out) {
+		return false, nil
+	}
+	if write {
+		if err := ioutil.WriteFile(file, out, info.Mode().Perm()); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wallyqs/dhall.go/imports"
+)
+
+const freezeHelpText = `usage: dhall-go freeze [--all] [--cache] [--vendor dir [--vendor-strict]] [file ...]
+
+Pins imports by adding their sha256 semantic hashes, keeping the rest
+of the source as it is.  With no files, freezes standard input to
+standard output; otherwise rewrites each file in place.
+`
+
+// runFreeze runs `dhall-go freeze` with args, and returns the exit
+// status.
+func runFreeze(args []string) int {
+	fs := flag.NewFlagSet("freeze", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, freezeHelpText)
+		fs.PrintDefaults()
+	}
+	var all, cache bool
+	fs.BoolVar(&all, "all", false, "Pin local and environment variable imports too, not only remote imports")
+	fs.BoolVar(&cache, "cache", false, "Pin imports as missing sha256:… ? import, falling back to the import if it isn't cached")
+	var vendor string
+	var strictVendor bool
+	addVendorFlags(fs, &vendor, &strictVendor)
+	fs.Parse(args)
+
+	loader, err := newLoader(vendor, strictVendor)
+	if err != nil {
+		printError(err, false)
+		return 1
+	}
+	var opts []imports.FreezeOption
+	if all {
+		opts = append(opts, imports.FreezeAll())
+	}
+	if cache {
+		opts = append(opts, imports.FreezeWithFallback())
+	}
+	return rewrite(fs.Args(), false, func(filename string, src []byte) ([]byte, error) {
+		return loader.Freeze(filename, src, opts...)
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -22,6 +23,9 @@ type config struct {
 	showHelp     bool
 	outputFormat string
 	file         string
+	explain      bool
+	vendor       string
+	strictVendor bool
 }
 
 const helpText = `dhall-go
@@ -33,12 +37,62 @@ Examples:
   # Output as JSON
   dhall-go -f file.dhall -o json
 
+  # Explain type errors in detail
+  dhall-go -f file.dhall --explain
+
+  # Print the normal form, type or semantic hash of an expression
+  dhall-go normalize -f file.dhall
+  dhall-go type < file.dhall
+  dhall-go hash -f file.dhall
+
+  # Format files in place, or check that they are formatted
+  dhall-go format file.dhall other.dhall
+  dhall-go format --check file.dhall
+
+  # List the files which a file imports, directly or indirectly, as a
+  # list, JSON or a Graphviz graph
+  dhall-go resolve -f file.dhall --dependencies
+  dhall-go resolve -f file.dhall --dependencies -o dot
+
+  # Pin the remote imports of a file with their semantic hashes
+  dhall-go freeze file.dhall
+
+  # Store the remote imports of a file, then resolve them only from
+  # the vendor directory, without the network
+  dhall-go vendor --dir vendor file.dhall
+  dhall-go normalize -f file.dhall --vendor vendor --vendor-strict
+  dhall-go -f file.dhall -o json --vendor vendor --vendor-strict
+
+  # List the cached imports, and remove the corrupt and unused ones
+  dhall-go cache list
+  dhall-go cache verify --remove
+  dhall-go cache prune --max-age 720h --max-size 500M
+
+Commands:
+  cache        List, verify and prune the cache of imported expressions.
+  format       Format Dhall source, keeping comments.
+  freeze       Pin imports with their semantic hashes.
+  hash         Print the semantic hash of a Dhall expression.
+  normalize    Print the normal form of a Dhall expression.
+  resolve      Resolve the imports of a Dhall expression, or list them.
+  type         Print the type of a Dhall expression.
+  vendor       Store the remote imports of Dhall files for offline use.
+
+Run "dhall-go <command> -h" for the flags of a command.  Without a
+command, dhall-go converts a Dhall file to YAML or JSON.
+
 Global Flags:
   -h, --help                    Show context-sensitive help.
       --version                 Show application version.
 `
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+
 	fs := flag.NewFlagSet("dhall-go", flag.ExitOnError)
 	fs.Usage = func() {
 		fmt.Printf("usage: dhall-go\n")
@@ -54,10 +108,12 @@ func main() {
 	fs.StringVar(&cfg.file, "file", "", "Configuration file")
 	fs.StringVar(&cfg.outputFormat, "o", "yaml", "Output format (yaml, json)")
 	fs.StringVar(&cfg.outputFormat, "output", "yaml", "Output format (yaml, json)")
+	fs.BoolVar(&cfg.explain, "explain", false, "Explain type errors in detail")
+	addVendorFlags(fs, &cfg.vendor, &cfg.strictVendor)
 	fs.Parse(os.Args[1:])
 
 	if cfg.showHelp {
-		fmt.Fprintln(os.Stderr, helpText)
+		fmt.Fprint(os.Stderr, helpText)
 		os.Exit(0)
 	}
 
@@ -65,10 +121,15 @@ func main() {
 		showVersionAndExit()
 	}
 
+	loader, err := newLoader(cfg.vendor, cfg.strictVendor)
+	if err != nil {
+		printError(err, cfg.explain)
+		os.Exit(1)
+	}
 	var data interface{}
-	err := dhall.UnmarshalFile(cfg.file, &data)
+	err = dhall.UnmarshalFileWithLoader(context.Background(), loader, cfg.file, &data)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, fmt.Errorf("dhall-go: %w", err))
+		printError(err, cfg.explain)
 		os.Exit(1)
 	}
 
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wallyqs/dhall.go/imports"
+	"github.com/wallyqs/dhall.go/printer"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// runResolve runs `dhall-go resolve` with args, and returns the exit
+// status.
+func runResolve(args []string) int {
+	in := &inputFlags{}
+	fs := in.flagSet("resolve", findCommand("resolve").description)
+	var dependencies bool
+	var output string
+	fs.BoolVar(&dependencies, "dependencies", false, "List the transitive imports instead of resolving them")
+	fs.StringVar(&output, "o", "list", "Output format of --dependencies (list, json, dot)")
+	fs.StringVar(&output, "output", "list", "Output format of --dependencies (list, json, dot)")
+	fs.Parse(args)
+	switch output {
+	case "list", "json", "dot":
+	default:
+		return in.fail(fmt.Errorf("undefined format %s", output))
+	}
+
+	expr, ancestors, err := in.parse()
+	if err != nil {
+		return in.fail(err)
+	}
+	loader, err := in.loader()
+	if err != nil {
+		return in.fail(err)
+	}
+	if !dependencies {
+		resolved, err := loader.Load(expr, ancestors...)
+		if err != nil {
+			return in.fail(err)
+		}
+		if err := printTerm(resolved); err != nil {
+			return in.fail(err)
+		}
+		return 0
+	}
+
+	deps, err := loader.Dependencies(expr, ancestors...)
+	if err != nil {
+		return in.fail(err)
+	}
+	switch output {
+	case "list":
+		err = printDependencyList(deps)
+	case "json":
+		err = printDependencyJSON(deps)
+	case "dot":
+		root := in.file
+		if root == "" {
+			root = "(stdin)"
+		}
+		err = printDependencyDot(root, deps)
+	}
+	if err != nil {
+		return in.fail(err)
+	}
+	return 0
+}
+
+// dependencyName returns the import of dep as Dhall source, without
+// its hash.
+func dependencyName(dep *imports.Dependency) (string, error) {
+	return printer.Sprint(term.Import{
+		ImportHashed: term.ImportHashed{Fetchable: dep.Fetchable},
+		ImportMode:   dep.Mode,
+	})
+}
+
+// printDependencyList prints each of the transitive dependencies once,
+// in the order they were imported, as pinned imports.
+func printDependencyList(deps []*imports.Dependency) error {
+	seen := make(map[string]bool)
+	var walk func([]*imports.Dependency) error
+	walk = func(deps []*imports.Dependency) error {
+		for _, dep := range deps {
+			s, err := printer.Sprint(term.Import{
+				ImportHashed: term.ImportHashed{Fetchable: dep.Fetchable, Hash: dep.Hash},
+				ImportMode:   dep.Mode,
+			})
+			if err != nil {
+				return err
+			}
+			if !seen[s] {
+				seen[s] = true
+				fmt.Println(s)
+			}
+			if err := walk(dep.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(deps)
+}
+
+// A jsonDependency is the JSON form of an imports.Dependency.
+type jsonDependency struct {
+	Import   string           `json:"import"`
+	Mode     string           `json:"mode"`
+	Hash     string           `json:"hash,omitempty"`
+	Children []jsonDependency `json:"children"`
+}
+
+var modeNames = map[term.ImportMode]string{
+	term.Code:     "code",
+	term.RawText:  "text",
+	term.RawBytes: "bytes",
+	term.Location: "location",
+}
+
+func toJSONDependencies(deps []*imports.Dependency) ([]jsonDependency, error) {
+	out := make([]jsonDependency, len(deps))
+	for i, dep := range deps {
+		name, err := dependencyName(dep)
+		if err != nil {
+			return nil, err
+		}
+		children, err := toJSONDependencies(dep.Children)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = jsonDependency{
+			Import:   name,
+			Mode:     modeNames[dep.Mode],
+			Children: children,
+		}
+		if dep.Hash != nil {
+			out[i].Hash = "sha256:" + hex.EncodeToString(dep.Hash[2:])
+		}
+	}
+	return out, nil
+}
+
+// printDependencyJSON prints the dependency graph as a JSON tree.
+func printDependencyJSON(deps []*imports.Dependency) error {
+	tree, err := toJSONDependencies(deps)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// printDependencyDot prints the dependency graph in Graphviz DOT
+// format, with an edge from each importing expression to each of its
+// imports.
+func printDependencyDot(root string, deps []*imports.Dependency) error {
+	var out strings.Builder
+	out.WriteString("digraph dependencies {\n")
+	seen := make(map[string]bool)
+	var walk func(from string, deps []*imports.Dependency) error
+	walk = func(from string, deps []*imports.Dependency) error {
+		for _, dep := range deps {
+			name, err := dependencyName(dep)
+			if err != nil {
+				return err
+			}
+			edge := fmt.Sprintf("  %s -> %s;\n", strconv.Quote(from), strconv.Quote(name))
+			if !seen[edge] {
+				seen[edge] = true
+				out.WriteString(edge)
+			}
+			if err := walk(name, dep.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root, deps); err != nil {
+		return err
+	}
+	out.WriteString("}\n")
+	fmt.Print(out.String())
+	return nil
+}
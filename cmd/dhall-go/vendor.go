@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wallyqs/dhall.go/imports"
+	"github.com/wallyqs/dhall.go/parser"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+const vendorHelpText = `usage: dhall-go vendor [--dir dir] [file ...]
+
+Resolves the imports of each file, or of standard input, and stores
+the source of every remote import they make, directly or indirectly,
+in a vendor directory, with a manifest mapping their URLs to files.
+Other commands then fetch remote imports from it with --vendor, or
+only from it with --vendor --vendor-strict.
+`
+
+// runVendor runs `dhall-go vendor` with args, and returns the exit
+// status.
+func runVendor(args []string) int {
+	fs := flag.NewFlagSet("vendor", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, vendorHelpText)
+		fs.PrintDefaults()
+	}
+	var dir string
+	fs.StringVar(&dir, "dir", "vendor", "The vendor directory")
+	fs.Parse(args)
+
+	loader := imports.NewLoader()
+	vendor := func(expr term.Term, ancestors ...term.Fetchable) error {
+		urls, err := loader.Vendor(dir, expr, ancestors...)
+		for _, url := range urls {
+			fmt.Println(url)
+		}
+		return err
+	}
+	if fs.NArg() == 0 {
+		expr, err := parser.ParseReader("(stdin)", os.Stdin, parser.WithSpans())
+		if err == nil {
+			err = vendor(expr)
+		}
+		if err != nil {
+			printError(err, false)
+			return 1
+		}
+		return 0
+	}
+	status := 0
+	for _, file := range fs.Args() {
+		expr, err := parser.ParseFile(file, parser.WithSpans())
+		if err == nil {
+			err = vendor(expr, term.LocalFile(file))
+		}
+		if err != nil {
+			printError(fmt.Errorf("%s: %w", file, err), false)
+			status = 1
+		}
+	}
+	return status
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"math"
+	"math/big"
 
 	"github.com/wallyqs/dhall.go/term"
 )
@@ -33,11 +34,15 @@ type Builtin string
 
 // These are the Builtins.
 const (
-	Double  Builtin = "Double"
-	Text    Builtin = "Text"
-	Bool    Builtin = "Bool"
-	Natural Builtin = "Natural"
-	Integer Builtin = "Integer"
+	Double   Builtin = "Double"
+	Text     Builtin = "Text"
+	Bool     Builtin = "Bool"
+	Natural  Builtin = "Natural"
+	Integer  Builtin = "Integer"
+	Date     Builtin = "Date"
+	Time     Builtin = "Time"
+	TimeZone Builtin = "TimeZone"
+	Bytes    Builtin = "Bytes"
 )
 
 // A BoolLit is a Value representing a Dhall boolean literal.
@@ -59,6 +64,10 @@ type (
 		typ  Value
 		succ Value
 		// zero Value
+
+		// the evaluator which produced the naturalFold, if any, so
+		// that long folds can be interrupted
+		ev *evaluator
 	}
 	naturalIsZero   struct{}
 	naturalOdd      struct{}
@@ -76,6 +85,12 @@ type (
 
 	doubleShow struct{}
 
+	dateShow     struct{}
+	timeShow     struct{}
+	timeZoneShow struct{}
+
+	bytesShow struct{}
+
 	optional struct{}
 	none     struct{}
 
@@ -121,6 +136,12 @@ func (integerToDouble) isValue() {}
 
 func (doubleShow) isValue() {}
 
+func (dateShow) isValue()     {}
+func (timeShow) isValue()     {}
+func (timeZoneShow) isValue() {}
+
+func (bytesShow) isValue() {}
+
 func (optional) isValue() {}
 func (none) isValue()     {}
 
@@ -283,6 +304,10 @@ type (
 	// A NaturalLit is a literal Value of type Natural.
 	NaturalLit uint
 
+	// A BigNaturalLit is a literal Value of type Natural which is
+	// too large to be represented as a NaturalLit.
+	BigNaturalLit struct{ *big.Int }
+
 	// An EmptyList is an empty list literal Value of the given type.
 	EmptyList struct{ Type Value }
 
@@ -316,6 +341,33 @@ type (
 	// An IntegerLit is a literal Value of type Integer.
 	IntegerLit int
 
+	// A BigIntegerLit is a literal Value of type Integer which is
+	// too large to be represented as an IntegerLit.
+	BigIntegerLit struct{ *big.Int }
+
+	// A DateLit is a literal Value of type Date.
+	DateLit struct {
+		Year  int
+		Month int
+		Day   int
+	}
+
+	// A TimeLit is a literal Value of type Time.  Fraction holds the
+	// digits after the seconds' decimal point, if any.
+	TimeLit struct {
+		Hour     int
+		Minute   int
+		Second   int
+		Fraction string
+	}
+
+	// A TimeZoneLit is a literal Value of type TimeZone, stored as
+	// an offset from UTC in minutes.
+	TimeZoneLit int
+
+	// A BytesLit is a literal Value of type Bytes.
+	BytesLit []byte
+
 	// Some represents a Value which is present in an Optional type.
 	Some struct{ Val Value }
 
@@ -370,9 +422,12 @@ type (
 	}
 
 	assert struct{ Annotation Value }
+
+	showConstructor struct{ Expr Value }
 )
 
-func (NaturalLit) isValue() {}
+func (NaturalLit) isValue()    {}
+func (BigNaturalLit) isValue() {}
 
 func (EmptyList) isValue()    {}
 func (NonEmptyList) isValue() {}
@@ -382,8 +437,14 @@ func (PlainTextLit) isValue()     {}
 
 func (ifVal) isValue() {}
 
-func (DoubleLit) isValue()  {}
-func (IntegerLit) isValue() {}
+func (DoubleLit) isValue()     {}
+func (IntegerLit) isValue()    {}
+func (BigIntegerLit) isValue() {}
+
+func (DateLit) isValue()     {}
+func (TimeLit) isValue()     {}
+func (TimeZoneLit) isValue() {}
+func (BytesLit) isValue()    {}
 
 func (d DoubleLit) String() string {
 	f := float64(d)
@@ -414,3 +475,4 @@ func (unionConstructor) isValue() {}
 func (unionVal) isValue()         {}
 func (merge) isValue()            {}
 func (assert) isValue()           {}
+func (showConstructor) isValue()  {}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"math"
+	"math/big"
+)
+
+// NewNaturalLit returns a Natural literal Value for n, which must not
+// be negative.  Small values are represented as a NaturalLit; only
+// values which don't fit are represented as a BigNaturalLit.
+func NewNaturalLit(n *big.Int) Value {
+	if n.IsUint64() && n.Uint64() <= math.MaxUint {
+		return NaturalLit(n.Uint64())
+	}
+	return BigNaturalLit{n}
+}
+
+// NewIntegerLit returns an Integer literal Value for i.  Small values
+// are represented as an IntegerLit; only values which don't fit are
+// represented as a BigIntegerLit.
+func NewIntegerLit(i *big.Int) Value {
+	if i.IsInt64() && i.Int64() >= math.MinInt && i.Int64() <= math.MaxInt {
+		return IntegerLit(i.Int64())
+	}
+	return BigIntegerLit{i}
+}
+
+// bigNatural returns the value of v as a *big.Int, if v is a Natural
+// literal.  The returned value is fresh and may be modified.
+func bigNatural(v Value) (*big.Int, bool) {
+	switch v := v.(type) {
+	case NaturalLit:
+		return new(big.Int).SetUint64(uint64(v)), true
+	case BigNaturalLit:
+		return new(big.Int).Set(v.Int), true
+	}
+	return nil, false
+}
+
+// bigInteger returns the value of v as a *big.Int, if v is an
+// Integer literal.  The returned value is fresh and may be modified.
+func bigInteger(v Value) (*big.Int, bool) {
+	switch v := v.(type) {
+	case IntegerLit:
+		return big.NewInt(int64(v)), true
+	case BigIntegerLit:
+		return new(big.Int).Set(v.Int), true
+	}
+	return nil, false
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/wallyqs/dhall.go/term"
@@ -12,8 +13,8 @@ func (naturalBuild) Call(x Value) Value {
 		Label:  "x",
 		Domain: Natural,
 		Fn: func(x Value) Value {
-			if n, ok := x.(NaturalLit); ok {
-				return NaturalLit(n + 1)
+			if n, ok := bigNatural(x); ok {
+				return NewNaturalLit(n.Add(n, big.NewInt(1)))
 			}
 			return oper{OpCode: term.PlusOp, L: x, R: NaturalLit(1)}
 		},
@@ -30,8 +31,8 @@ func (naturalBuild) ArgType() Value {
 }
 
 func (naturalEven) Call(x Value) Value {
-	if n, ok := x.(NaturalLit); ok {
-		return BoolLit(n%2 == 0)
+	if n, ok := bigNatural(x); ok {
+		return BoolLit(n.Bit(0) == 0)
 	}
 	return nil
 }
@@ -40,12 +41,13 @@ func (naturalEven) ArgType() Value { return Natural }
 
 func (fold naturalFold) Call(x Value) Value {
 	if fold.n == nil {
-		return naturalFold{n: x}
+		return naturalFold{n: x, ev: fold.ev}
 	}
 	if fold.typ == nil {
 		return naturalFold{
 			n:   fold.n,
 			typ: x,
+			ev:  fold.ev,
 		}
 	}
 	if fold.succ == nil {
@@ -53,12 +55,23 @@ func (fold naturalFold) Call(x Value) Value {
 			n:    fold.n,
 			typ:  fold.typ,
 			succ: x,
+			ev:   fold.ev,
 		}
 	}
 	zero := x
-	if n, ok := fold.n.(NaturalLit); ok {
+	switch n := fold.n.(type) {
+	case NaturalLit:
 		result := zero
-		for i := 0; i < int(n); i++ {
+		for i := NaturalLit(0); i < n; i++ {
+			fold.ev.reduce()
+			result = apply(fold.succ, result)
+		}
+		return result
+	case BigNaturalLit:
+		result := zero
+		one := big.NewInt(1)
+		for i := new(big.Int); i.Cmp(n.Int) < 0; i.Add(i, one) {
+			fold.ev.reduce()
 			result = apply(fold.succ, result)
 		}
 		return result
@@ -81,8 +94,8 @@ func (fold naturalFold) ArgType() Value {
 }
 
 func (naturalIsZero) Call(x Value) Value {
-	if n, ok := x.(NaturalLit); ok {
-		return BoolLit(n == 0)
+	if n, ok := bigNatural(x); ok {
+		return BoolLit(n.Sign() == 0)
 	}
 	return nil
 }
@@ -90,8 +103,8 @@ func (naturalIsZero) Call(x Value) Value {
 func (naturalIsZero) ArgType() Value { return Natural }
 
 func (naturalOdd) Call(x Value) Value {
-	if n, ok := x.(NaturalLit); ok {
-		return BoolLit(n%2 == 1)
+	if n, ok := bigNatural(x); ok {
+		return BoolLit(n.Bit(0) == 1)
 	}
 	return nil
 }
@@ -99,8 +112,8 @@ func (naturalOdd) Call(x Value) Value {
 func (naturalOdd) ArgType() Value { return Natural }
 
 func (naturalShow) Call(x Value) Value {
-	if n, ok := x.(NaturalLit); ok {
-		return PlainTextLit(fmt.Sprintf("%d", n))
+	if n, ok := bigNatural(x); ok {
+		return PlainTextLit(n.String())
 	}
 	return nil
 }
@@ -111,11 +124,11 @@ func (sub naturalSubtract) Call(x Value) Value {
 	if sub.a == nil {
 		return naturalSubtract{a: x}
 	}
-	m, mok := sub.a.(NaturalLit)
-	n, nok := x.(NaturalLit)
+	m, mok := bigNatural(sub.a)
+	n, nok := bigNatural(x)
 	if mok && nok {
-		if n >= m {
-			return NaturalLit(n - m)
+		if n.Cmp(m) >= 0 {
+			return NewNaturalLit(n.Sub(n, m))
 		}
 		return NaturalLit(0)
 	}
@@ -134,8 +147,8 @@ func (sub naturalSubtract) Call(x Value) Value {
 func (naturalSubtract) ArgType() Value { return Natural }
 
 func (naturalToInteger) Call(x Value) Value {
-	if n, ok := x.(NaturalLit); ok {
-		return IntegerLit(n)
+	if n, ok := bigNatural(x); ok {
+		return NewIntegerLit(n)
 	}
 	return nil
 }
@@ -143,11 +156,11 @@ func (naturalToInteger) Call(x Value) Value {
 func (naturalToInteger) ArgType() Value { return Natural }
 
 func (integerClamp) Call(x Value) Value {
-	if i, ok := x.(IntegerLit); ok {
-		if i < 0 {
+	if i, ok := bigInteger(x); ok {
+		if i.Sign() < 0 {
 			return NaturalLit(0)
 		}
-		return NaturalLit(i)
+		return NewNaturalLit(i)
 	}
 	return nil
 }
@@ -155,8 +168,8 @@ func (integerClamp) Call(x Value) Value {
 func (integerClamp) ArgType() Value { return Integer }
 
 func (integerNegate) Call(x Value) Value {
-	if i, ok := x.(IntegerLit); ok {
-		return IntegerLit(-i)
+	if i, ok := bigInteger(x); ok {
+		return NewIntegerLit(i.Neg(i))
 	}
 	return nil
 }
@@ -164,7 +177,7 @@ func (integerNegate) Call(x Value) Value {
 func (integerNegate) ArgType() Value { return Integer }
 
 func (integerShow) Call(x Value) Value {
-	if i, ok := x.(IntegerLit); ok {
+	if i, ok := bigInteger(x); ok {
 		return PlainTextLit(fmt.Sprintf("%+d", i))
 	}
 	return nil
@@ -173,8 +186,14 @@ func (integerShow) Call(x Value) Value {
 func (integerShow) ArgType() Value { return Integer }
 
 func (integerToDouble) Call(x Value) Value {
-	if i, ok := x.(IntegerLit); ok {
+	switch i := x.(type) {
+	case IntegerLit:
 		return DoubleLit(i)
+	case BigIntegerLit:
+		// SetInt gives the big.Float enough precision to hold i
+		// exactly, so Float64 rounds only once, to nearest even
+		f, _ := new(big.Float).SetInt(i.Int).Float64()
+		return DoubleLit(f)
 	}
 	return nil
 }
@@ -190,6 +209,42 @@ func (doubleShow) Call(x Value) Value {
 
 func (doubleShow) ArgType() Value { return Double }
 
+func (dateShow) Call(x Value) Value {
+	if d, ok := x.(DateLit); ok {
+		return PlainTextLit(term.DateLit(d).String())
+	}
+	return nil
+}
+
+func (dateShow) ArgType() Value { return Date }
+
+func (timeShow) Call(x Value) Value {
+	if t, ok := x.(TimeLit); ok {
+		return PlainTextLit(term.TimeLit(t).String())
+	}
+	return nil
+}
+
+func (timeShow) ArgType() Value { return Time }
+
+func (timeZoneShow) Call(x Value) Value {
+	if z, ok := x.(TimeZoneLit); ok {
+		return PlainTextLit(term.TimeZoneLit(z).String())
+	}
+	return nil
+}
+
+func (timeZoneShow) ArgType() Value { return TimeZone }
+
+func (bytesShow) Call(x Value) Value {
+	if b, ok := x.(BytesLit); ok {
+		return PlainTextLit(fmt.Sprintf(`0x"%X"`, []byte(b)))
+	}
+	return nil
+}
+
+func (bytesShow) ArgType() Value { return Bytes }
+
 func (optional) Call(x Value) Value { return OptionalOf{x} }
 func (optional) ArgType() Value     { return Type }
 
@@ -483,6 +538,12 @@ var (
 	IntegerToDouble  Callable = integerToDouble{}
 	DoubleShow       Callable = doubleShow{}
 
+	DateShow     Callable = dateShow{}
+	TimeShow     Callable = timeShow{}
+	TimeZoneShow Callable = timeZoneShow{}
+
+	BytesShow Callable = bytesShow{}
+
 	Optional Callable = optional{}
 	None     Callable = none{}
 
@@ -1,6 +1,8 @@
 package core_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/wallyqs/dhall.go/core"
@@ -55,4 +57,39 @@ var _ = DescribeTable("ArgType of builtins", func(src, typ string) {
 	Entry("Integer/clamp", `Integer/clamp`, `Integer`),
 
 	Entry("Double/show", `Double/show`, `Double`),
+
+	Entry("Date/show", `Date/show`, `Date`),
+	Entry("Time/show", `Time/show`, `Time`),
+	Entry("TimeZone/show", `TimeZone/show`, `TimeZone`),
+
+	Entry("Bytes/show", `Bytes/show`, `Bytes`),
+)
+
+var _ = DescribeTable("Arbitrary-precision Natural and Integer", func(src, expected string) {
+	term, err := parser.Parse("-", []byte(src))
+	Expect(err).To(Not(HaveOccurred()))
+	expectedTerm, err := parser.Parse("-", []byte(expected))
+	Expect(err).To(Not(HaveOccurred()))
+	Expect(core.Eval(term)).
+		To(core.BeAlphaEquivalentTo(core.Eval(expectedTerm)))
+},
+	Entry("Natural addition overflowing uint64", `18446744073709551615 + 1`, `18446744073709551616`),
+	Entry("Natural multiplication overflowing uint64", `4294967296 * 4294967296`, `18446744073709551616`),
+	Entry("big Natural addition", `18446744073709551616 + 18446744073709551616`, `36893488147419103232`),
+	Entry("big Natural times zero", `18446744073709551616 * 0`, `0`),
+	Entry("Natural/subtract back to small", `Natural/subtract 18446744073709551615 18446744073709551616`, `1`),
+	Entry("Natural/subtract clamps to zero", `Natural/subtract 18446744073709551616 1`, `0`),
+	Entry("Natural/show", `Natural/show 18446744073709551616`, `"18446744073709551616"`),
+	Entry("Natural/even", `Natural/even 18446744073709551616`, `True`),
+	Entry("Natural/odd", `Natural/odd 18446744073709551617`, `True`),
+	Entry("Natural/isZero", `Natural/isZero 18446744073709551616`, `False`),
+	Entry("Natural/toInteger", `Natural/toInteger 18446744073709551615`, `+18446744073709551615`),
+	Entry("Natural/build", `Natural/build (λ(natural : Type) → λ(succ : natural → natural) → λ(zero : natural) → succ (succ zero)) `, `2`),
+	Entry("Integer/negate of min int64", `Integer/negate -9223372036854775808`, `+9223372036854775808`),
+	Entry("Integer/clamp", `Integer/clamp +18446744073709551616`, `18446744073709551616`),
+	Entry("Integer/clamp negative", `Integer/clamp -18446744073709551616`, `0`),
+	Entry("Integer/show", `Integer/show -18446744073709551616`, `"-18446744073709551616"`),
+	Entry("Integer/toDouble rounds to nearest", `Integer/toDouble +18446744073709551617`, `1.8446744073709552e19`),
+	Entry("Integer/toDouble overflows to Infinity", `Integer/toDouble -`+
+		`1`+strings.Repeat("0", 400), `-Infinity`),
 )
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"math"
 )
 
@@ -9,24 +10,50 @@ import (
 // values are alpha-equivalent if they are structurally identical,
 // ignoring label names.
 func AlphaEquivalent(v1 Value, v2 Value) bool {
-	return alphaEquivalentWith(0, v1, v2)
+	return alphaEquivalentWith(nil, 0, v1, v2)
 }
 
-func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
+// alphaEquivalent is AlphaEquivalent, subject to ev's depth limit.
+func (ev *evaluator) alphaEquivalent(v1 Value, v2 Value) bool {
+	return alphaEquivalentWith(ev, 0, v1, v2)
+}
+
+func alphaEquivalentWith(ev *evaluator, level int, v1 Value, v2 Value) bool {
+	ev.enter()
+	defer ev.leave()
 	switch v1 := v1.(type) {
 	case Universe, Builtin,
-		naturalBuild, naturalEven, naturalFold,
+		naturalBuild, naturalEven,
 		naturalIsZero, naturalOdd, naturalShow,
 		naturalSubtract, naturalToInteger,
 		integerShow, integerClamp, integerNegate, integerToDouble,
 		doubleShow,
+		dateShow, timeShow, timeZoneShow, bytesShow,
 		optional, none,
 		textShow, textReplace,
 		list, listBuild, listFold, listHead, listIndexed,
 		listLength, listLast, listReverse,
 		freeVar, localVar, quoteVar,
-		NaturalLit, IntegerLit, BoolLit, PlainTextLit:
+		NaturalLit, IntegerLit, BoolLit, PlainTextLit,
+		DateLit, TimeLit, TimeZoneLit:
 		return v1 == v2
+	case naturalFold:
+		v2, ok := v2.(naturalFold)
+		if !ok {
+			return false
+		}
+		// ignore the evaluator which produced each fold
+		v1.ev, v2.ev = nil, nil
+		return v1 == v2
+	case BytesLit:
+		v2, ok := v2.(BytesLit)
+		return ok && bytes.Equal(v1, v2)
+	case BigNaturalLit:
+		v2, ok := v2.(BigNaturalLit)
+		return ok && v1.Cmp(v2.Int) == 0
+	case BigIntegerLit:
+		v2, ok := v2.(BigIntegerLit)
+		return ok && v1.Cmp(v2.Int) == 0
 	case DoubleLit:
 		v2, ok := v2.(DoubleLit)
 		return ok && v1 == v2 && math.Signbit(float64(v1)) == math.Signbit(float64(v2))
@@ -36,9 +63,9 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 			return false
 		}
 		// we deliberately ignore the Labels here
-		return alphaEquivalentWith(level, v1.Domain, v2.Domain) &&
+		return alphaEquivalentWith(ev, level, v1.Domain, v2.Domain) &&
 			alphaEquivalentWith(
-				level+1,
+				ev, level+1,
 				v1.Call(quoteVar{Name: "_", Index: level}),
 				v2.Call(quoteVar{Name: "_", Index: level}),
 			)
@@ -47,9 +74,9 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Domain, v2.Domain) &&
+		return alphaEquivalentWith(ev, level, v1.Domain, v2.Domain) &&
 			alphaEquivalentWith(
-				level+1,
+				ev, level+1,
 				v1.Codomain(quoteVar{Name: "_", Index: level}),
 				v2.Codomain(quoteVar{Name: "_", Index: level}),
 			)
@@ -58,28 +85,28 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Fn, v2.Fn) &&
-			alphaEquivalentWith(level, v1.Arg, v2.Arg)
+		return alphaEquivalentWith(ev, level, v1.Fn, v2.Fn) &&
+			alphaEquivalentWith(ev, level, v1.Arg, v2.Arg)
 	case oper:
 		v2, ok := v2.(oper)
 		if !ok {
 			return false
 		}
 		return v1.OpCode == v2.OpCode &&
-			alphaEquivalentWith(level, v1.L, v2.L) &&
-			alphaEquivalentWith(level, v1.R, v2.R)
+			alphaEquivalentWith(ev, level, v1.L, v2.L) &&
+			alphaEquivalentWith(ev, level, v1.R, v2.R)
 	case ListOf:
 		v2, ok := v2.(ListOf)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case EmptyList:
 		v2, ok := v2.(EmptyList)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case NonEmptyList:
 		v2, ok := v2.(NonEmptyList)
 		if !ok {
@@ -89,7 +116,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 			return false
 		}
 		for i := range v1 {
-			if !alphaEquivalentWith(level, v1[i], v2[i]) {
+			if !alphaEquivalentWith(ev, level, v1[i], v2[i]) {
 				return false
 			}
 		}
@@ -108,7 +135,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 			if c1.Prefix != c2.Prefix {
 				return false
 			}
-			if !alphaEquivalentWith(level, c1.Expr, c2.Expr) {
+			if !alphaEquivalentWith(ev, level, c1.Expr, c2.Expr) {
 				return false
 			}
 		}
@@ -118,27 +145,27 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Cond, v2.Cond) &&
-			alphaEquivalentWith(level, v1.T, v2.T) &&
-			alphaEquivalentWith(level, v1.F, v2.F)
+		return alphaEquivalentWith(ev, level, v1.Cond, v2.Cond) &&
+			alphaEquivalentWith(ev, level, v1.T, v2.T) &&
+			alphaEquivalentWith(ev, level, v1.F, v2.F)
 	case OptionalOf:
 		v2, ok := v2.(OptionalOf)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case Some:
 		v2, ok := v2.(Some)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Val, v2.Val)
+		return alphaEquivalentWith(ev, level, v1.Val, v2.Val)
 	case NoneOf:
 		v2, ok := v2.(NoneOf)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case RecordType:
 		v2, ok := v2.(RecordType)
 		if !ok {
@@ -149,7 +176,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		}
 		for k := range v1 {
 			if v2[k] == nil ||
-				!alphaEquivalentWith(level, v1[k], v2[k]) {
+				!alphaEquivalentWith(ev, level, v1[k], v2[k]) {
 				return false
 			}
 		}
@@ -164,7 +191,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		}
 		for k := range v1 {
 			if v2[k] == nil ||
-				!alphaEquivalentWith(level, v1[k], v2[k]) {
+				!alphaEquivalentWith(ev, level, v1[k], v2[k]) {
 				return false
 			}
 		}
@@ -174,15 +201,15 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Record, v2.Record) &&
-			alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Record, v2.Record) &&
+			alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case field:
 		v2, ok := v2.(field)
 		if !ok {
 			return false
 		}
 		return v1.FieldName == v2.FieldName &&
-			alphaEquivalentWith(level, v1.Record, v2.Record)
+			alphaEquivalentWith(ev, level, v1.Record, v2.Record)
 	case project:
 		v2, ok := v2.(project)
 		if !ok {
@@ -196,7 +223,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 				return false
 			}
 		}
-		return alphaEquivalentWith(level, v1.Record, v2.Record)
+		return alphaEquivalentWith(ev, level, v1.Record, v2.Record)
 	case with:
 		v2, ok := v2.(with)
 		if !ok {
@@ -210,8 +237,8 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 				return false
 			}
 		}
-		return alphaEquivalentWith(level, v1.Record, v2.Record) &&
-			alphaEquivalentWith(level, v1.Value, v2.Value)
+		return alphaEquivalentWith(ev, level, v1.Record, v2.Record) &&
+			alphaEquivalentWith(ev, level, v1.Value, v2.Value)
 	case UnionType:
 		v2, ok := v2.(UnionType)
 		if !ok {
@@ -227,7 +254,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 				}
 				continue
 			}
-			if !alphaEquivalentWith(level, v1[k], v2[k]) {
+			if !alphaEquivalentWith(ev, level, v1[k], v2[k]) {
 				return false
 			}
 		}
@@ -240,7 +267,7 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 		if v1.Alternative != v2.Alternative {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case unionVal:
 		v2, ok := v2.(unionVal)
 		if !ok {
@@ -253,11 +280,11 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 			if v2.Val == nil {
 				return false
 			}
-			if !alphaEquivalentWith(level, v1.Val, v2.Val) {
+			if !alphaEquivalentWith(ev, level, v1.Val, v2.Val) {
 				return false
 			}
 		}
-		return alphaEquivalentWith(level, v1.Type, v2.Type)
+		return alphaEquivalentWith(ev, level, v1.Type, v2.Type)
 	case merge:
 		v2, ok := v2.(merge)
 		if !ok {
@@ -267,18 +294,24 @@ func alphaEquivalentWith(level int, v1 Value, v2 Value) bool {
 			if v2.Annotation == nil {
 				return false
 			}
-			if !alphaEquivalentWith(level, v1.Annotation, v2.Annotation) {
+			if !alphaEquivalentWith(ev, level, v1.Annotation, v2.Annotation) {
 				return false
 			}
 		}
-		return alphaEquivalentWith(level, v1.Handler, v2.Handler) &&
-			alphaEquivalentWith(level, v1.Union, v2.Union)
+		return alphaEquivalentWith(ev, level, v1.Handler, v2.Handler) &&
+			alphaEquivalentWith(ev, level, v1.Union, v2.Union)
 	case assert:
 		v2, ok := v2.(assert)
 		if !ok {
 			return false
 		}
-		return alphaEquivalentWith(level, v1.Annotation, v2.Annotation)
+		return alphaEquivalentWith(ev, level, v1.Annotation, v2.Annotation)
+	case showConstructor:
+		v2, ok := v2.(showConstructor)
+		if !ok {
+			return false
+		}
+		return alphaEquivalentWith(ev, level, v1.Expr, v2.Expr)
 	}
 	panic("unknown Value type")
 }
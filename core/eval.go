@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"math/bits"
 	"sort"
 
 	"github.com/wallyqs/dhall.go/term"
@@ -16,6 +17,17 @@ func Eval(t term.Term) Value {
 }
 
 func evalWith(t term.Term, e env) Value {
+	var ev *evaluator
+	return ev.eval(t, e)
+}
+
+func (ev *evaluator) eval(t term.Term, e env) Value {
+	ev.enter()
+	defer ev.leave()
+	return ev.limitSize(ev.evalNode(t, e))
+}
+
+func (ev *evaluator) evalNode(t term.Term, e env) Value {
 	switch t := t.(type) {
 	case term.Universe:
 		return Universe(t)
@@ -30,7 +42,7 @@ func evalWith(t term.Term, e env) Value {
 		case term.NaturalEven:
 			return NaturalEven
 		case term.NaturalFold:
-			return NaturalFold
+			return naturalFold{ev: ev}
 		case term.NaturalIsZero:
 			return NaturalIsZero
 		case term.NaturalOdd:
@@ -65,6 +77,14 @@ func evalWith(t term.Term, e env) Value {
 			return TextShow
 		case term.TextReplace:
 			return TextReplace
+		case term.DateShow:
+			return DateShow
+		case term.TimeShow:
+			return TimeShow
+		case term.TimeZoneShow:
+			return TimeZoneShow
+		case term.BytesShow:
+			return BytesShow
 		case term.List:
 			return List
 		case term.ListBuild:
@@ -94,31 +114,32 @@ func evalWith(t term.Term, e env) Value {
 	case term.Lambda:
 		return lambda{
 			Label:  t.Label,
-			Domain: evalWith(t.Type, e),
+			Domain: ev.eval(t.Type, e),
 			Fn: func(x Value) Value {
+				ev.reduce()
 				newEnv := env{}
 				for k, v := range e {
 					newEnv[k] = v
 				}
 				newEnv[t.Label] = append([]Value{x}, newEnv[t.Label]...)
-				return evalWith(t.Body, newEnv)
+				return ev.eval(t.Body, newEnv)
 			},
 		}
 	case term.Pi:
 		return Pi{
 			Label:  t.Label,
-			Domain: evalWith(t.Type, e),
+			Domain: ev.eval(t.Type, e),
 			Codomain: func(x Value) Value {
 				newEnv := env{}
 				for k, v := range e {
 					newEnv[k] = v
 				}
 				newEnv[t.Label] = append([]Value{x}, newEnv[t.Label]...)
-				return evalWith(t.Body, newEnv)
+				return ev.eval(t.Body, newEnv)
 			}}
 	case term.App:
-		fn := evalWith(t.Fn, e)
-		arg := evalWith(t.Arg, e)
+		fn := ev.eval(t.Fn, e)
+		arg := ev.eval(t.Arg, e)
 		return apply(fn, arg)
 	case term.Let:
 		newEnv := env{}
@@ -127,19 +148,20 @@ func evalWith(t term.Term, e env) Value {
 		}
 
 		for _, b := range t.Bindings {
-			val := evalWith(b.Value, newEnv)
+			ev.reduce()
+			val := ev.eval(b.Value, newEnv)
 			newEnv[b.Variable] = append([]Value{val}, newEnv[b.Variable]...)
 		}
-		return evalWith(t.Body, newEnv)
+		return ev.eval(t.Body, newEnv)
 	case term.Annot:
-		return evalWith(t.Expr, e)
+		return ev.eval(t.Expr, e)
 	case term.DoubleLit:
 		return DoubleLit(t)
 	case term.TextLit:
 		text := &textValBuilder{}
 		for _, chk := range t.Chunks {
 			text.appendStr(chk.Prefix)
-			normExpr := evalWith(chk.Expr, e)
+			normExpr := ev.eval(chk.Expr, e)
 			text.appendValue(normExpr)
 		}
 		text.appendStr(t.Suffix)
@@ -148,39 +170,53 @@ func evalWith(t term.Term, e env) Value {
 	case term.BoolLit:
 		return BoolLit(t)
 	case term.If:
-		condVal := evalWith(t.Cond, e)
+		condVal := ev.eval(t.Cond, e)
 		if condVal == True {
-			return evalWith(t.T, e)
+			return ev.eval(t.T, e)
 		}
 		if condVal == False {
-			return evalWith(t.F, e)
+			return ev.eval(t.F, e)
 		}
-		tVal := evalWith(t.T, e)
-		fVal := evalWith(t.F, e)
+		tVal := ev.eval(t.T, e)
+		fVal := ev.eval(t.F, e)
 		if tVal == True && fVal == False {
 			return condVal
 		}
-		if AlphaEquivalent(tVal, fVal) {
+		if ev.alphaEquivalent(tVal, fVal) {
 			return tVal
 		}
 		return ifVal{
 			Cond: condVal,
-			T:    evalWith(t.T, e),
-			F:    evalWith(t.F, e),
+			T:    ev.eval(t.T, e),
+			F:    ev.eval(t.F, e),
 		}
 	case term.NaturalLit:
 		return NaturalLit(t)
 	case term.IntegerLit:
 		return IntegerLit(t)
+	case term.BigNaturalLit:
+		// a BigNaturalLit may hold a small value, if it wasn't
+		// parsed; keep the Value canonical
+		return NewNaturalLit(t.Int)
+	case term.BigIntegerLit:
+		return NewIntegerLit(t.Int)
+	case term.DateLit:
+		return DateLit(t)
+	case term.TimeLit:
+		return TimeLit(t)
+	case term.TimeZoneLit:
+		return TimeZoneLit(t)
+	case term.BytesLit:
+		return BytesLit(t)
 	case term.Op:
 		// these are cases where we *don't* evaluate t.L and t.R up front
 		switch t.OpCode {
 		case term.TextAppendOp:
-			return evalWith(
+			return ev.eval(
 				term.TextLit{Chunks: term.Chunks{{Expr: t.L}, {Expr: t.R}}},
 				e)
 		case term.CompleteOp:
-			return evalWith(
+			return ev.eval(
 				term.Annot{
 					Expr: term.Op{
 						OpCode: term.RightBiasedRecordMergeOp,
@@ -191,8 +227,8 @@ func evalWith(t term.Term, e env) Value {
 				},
 				e)
 		}
-		l := evalWith(t.L, e)
-		r := evalWith(t.R, e)
+		l := ev.eval(t.L, e)
+		r := ev.eval(t.R, e)
 		switch t.OpCode {
 		case term.OrOp, term.AndOp, term.EqOp, term.NeOp:
 			lb, lok := l.(BoolLit)
@@ -211,7 +247,7 @@ func evalWith(t term.Term, e env) Value {
 					}
 					return l
 				}
-				if AlphaEquivalent(l, r) {
+				if ev.alphaEquivalent(l, r) {
 					return l
 				}
 			case term.AndOp:
@@ -227,7 +263,7 @@ func evalWith(t term.Term, e env) Value {
 					}
 					return False
 				}
-				if AlphaEquivalent(l, r) {
+				if ev.alphaEquivalent(l, r) {
 					return l
 				}
 			case term.EqOp:
@@ -237,7 +273,7 @@ func evalWith(t term.Term, e env) Value {
 				if rok && bool(rb) {
 					return l
 				}
-				if AlphaEquivalent(l, r) {
+				if ev.alphaEquivalent(l, r) {
 					return True
 				}
 			case term.NeOp:
@@ -247,7 +283,7 @@ func evalWith(t term.Term, e env) Value {
 				if rok && !bool(rb) {
 					return l
 				}
-				if AlphaEquivalent(l, r) {
+				if ev.alphaEquivalent(l, r) {
 					return False
 				}
 			}
@@ -267,7 +303,14 @@ func evalWith(t term.Term, e env) Value {
 			ln, lok := l.(NaturalLit)
 			rn, rok := r.(NaturalLit)
 			if lok && rok {
-				return NaturalLit(ln + rn)
+				if sum, carry := bits.Add(uint(ln), uint(rn), 0); carry == 0 {
+					return NaturalLit(sum)
+				}
+			}
+			if lb, ok := bigNatural(l); ok {
+				if rb, ok := bigNatural(r); ok {
+					return NewNaturalLit(lb.Add(lb, rb))
+				}
 			}
 			if l == NaturalLit(0) {
 				return r
@@ -279,7 +322,14 @@ func evalWith(t term.Term, e env) Value {
 			ln, lok := l.(NaturalLit)
 			rn, rok := r.(NaturalLit)
 			if lok && rok {
-				return NaturalLit(ln * rn)
+				if hi, lo := bits.Mul(uint(ln), uint(rn)); hi == 0 {
+					return NaturalLit(lo)
+				}
+			}
+			if lb, ok := bigNatural(l); ok {
+				if rb, ok := bigNatural(r); ok {
+					return NewNaturalLit(lb.Mul(lb, rb))
+				}
 			}
 			if l == NaturalLit(0) {
 				return NaturalLit(0)
@@ -342,7 +392,7 @@ func evalWith(t term.Term, e env) Value {
 				}
 				return result
 			}
-			if AlphaEquivalent(l, r) {
+			if ev.alphaEquivalent(l, r) {
 				return l
 			}
 		case term.ImportAltOp:
@@ -352,33 +402,33 @@ func evalWith(t term.Term, e env) Value {
 		}
 		return oper{OpCode: t.OpCode, L: l, R: r}
 	case term.EmptyList:
-		return EmptyList{Type: evalWith(t.Type, e)}
+		return EmptyList{Type: ev.eval(t.Type, e)}
 	case term.NonEmptyList:
 		result := make([]Value, len(t))
 		for i, t := range t {
-			result[i] = evalWith(t, e)
+			result[i] = ev.eval(t, e)
 		}
 		return NonEmptyList(result)
 	case term.Some:
-		return Some{evalWith(t.Val, e)}
+		return Some{ev.eval(t.Val, e)}
 	case term.RecordType:
 		newRT := RecordType{}
 		for k, v := range t {
-			newRT[k] = evalWith(v, e)
+			newRT[k] = ev.eval(v, e)
 		}
 		return newRT
 	case term.RecordLit:
 		newRT := RecordLit{}
 		for k, v := range t {
-			newRT[k] = evalWith(v, e)
+			newRT[k] = ev.eval(v, e)
 		}
 		return newRT
 	case term.ToMap:
-		recordVal := evalWith(t.Record, e)
+		recordVal := ev.eval(t.Record, e)
 		record, ok := recordVal.(RecordLit)
 		if ok {
 			if len(record) == 0 {
-				return EmptyList{Type: evalWith(t.Type, e)}
+				return EmptyList{Type: ev.eval(t.Type, e)}
 			}
 			fieldnames := []string{}
 			for k := range record {
@@ -393,11 +443,11 @@ func evalWith(t term.Term, e env) Value {
 		}
 		toMapVal := toMap{Record: recordVal}
 		if t.Type != nil {
-			toMapVal.Type = evalWith(t.Type, e)
+			toMapVal.Type = ev.eval(t.Type, e)
 		}
 		return toMapVal
 	case term.Field:
-		record := evalWith(t.Record, e)
+		record := ev.eval(t.Record, e)
 		for { // simplifications
 			if proj, ok := record.(project); ok {
 				record = proj.Record
@@ -479,7 +529,7 @@ func evalWith(t term.Term, e env) Value {
 			FieldName: t.FieldName,
 		}
 	case term.Project:
-		record := evalWith(t.Record, e)
+		record := ev.eval(t.Record, e)
 		fieldNames := t.FieldNames
 		sort.Strings(fieldNames)
 		// simplifications
@@ -533,12 +583,12 @@ func evalWith(t term.Term, e env) Value {
 	case term.ProjectType:
 		// if `t` typechecks, `t.Selector` has to eval to a
 		// RecordTypeVal, so this is safe
-		s := evalWith(t.Selector, e).(RecordType)
+		s := ev.eval(t.Selector, e).(RecordType)
 		fieldNames := make([]string, 0, len(s))
 		for fieldName := range s {
 			fieldNames = append(fieldNames, fieldName)
 		}
-		return evalWith(
+		return ev.eval(
 			term.Project{
 				Record:     t.Record,
 				FieldNames: fieldNames,
@@ -551,12 +601,12 @@ func evalWith(t term.Term, e env) Value {
 				result[k] = nil
 				continue
 			}
-			result[k] = evalWith(v, e)
+			result[k] = ev.eval(v, e)
 		}
 		return result
 	case term.Merge:
-		handlerVal := evalWith(t.Handler, e)
-		union := evalWith(t.Union, e)
+		handlerVal := ev.eval(t.Handler, e)
+		union := ev.eval(t.Union, e)
 		if handlers, ok := handlerVal.(RecordLit); ok {
 			if unionLit, ok := union.(unionVal); ok {
 				if unionLit.Val == nil {
@@ -582,16 +632,29 @@ func evalWith(t term.Term, e env) Value {
 			Union:   union,
 		}
 		if t.Annotation != nil {
-			output.Annotation = evalWith(t.Annotation, e)
+			output.Annotation = ev.eval(t.Annotation, e)
 		}
 		return output
 	case term.Assert:
-		return assert{Annotation: evalWith(t.Annotation, e)}
+		return assert{Annotation: ev.eval(t.Annotation, e)}
 	case term.With:
-		record := evalWith(t.Record, e)
-		value := evalWith(t.Value, e)
+		record := ev.eval(t.Record, e)
+		value := ev.eval(t.Value, e)
 
 		return withRule(record, t.Path, value)
+	case term.ShowConstructor:
+		expr := ev.eval(t.Expr, e)
+		switch expr := expr.(type) {
+		case unionVal:
+			return PlainTextLit(expr.Alternative)
+		case Some:
+			return PlainTextLit("Some")
+		case NoneOf:
+			return PlainTextLit("None")
+		}
+		return showConstructor{Expr: expr}
+	case term.Note:
+		return ev.evalNode(t.Term, e)
 	default:
 		panic(fmt.Sprint("unknown term type", t))
 	}
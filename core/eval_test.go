@@ -1,7 +1,12 @@
 package core
 
 import (
+	"context"
+	"math/big"
+	"time"
+
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/wallyqs/dhall.go/term"
 )
@@ -38,6 +43,29 @@ var _ = Describe("Eval", func() {
 				To(Equal(Type))
 		})
 	})
+	Describe("temporal builtins", func() {
+		It("Date/show", func() {
+			Expect(Eval(term.Apply(term.DateShow, term.DateLit{2000, 3, 4}))).
+				To(Equal(PlainTextLit("2000-03-04")))
+		})
+		It("Time/show preserves precision", func() {
+			Expect(Eval(term.Apply(term.TimeShow, term.TimeLit{1, 2, 3, "500"}))).
+				To(Equal(PlainTextLit("01:02:03.500")))
+		})
+		It("TimeZone/show", func() {
+			Expect(Eval(term.Apply(term.TimeZoneShow, term.TimeZoneLit(-330)))).
+				To(Equal(PlainTextLit("-05:30")))
+		})
+	})
+	It("big literals with small values", func() {
+		Expect(Eval(term.BigNaturalLit{Int: big.NewInt(3)})).To(Equal(NaturalLit(3)))
+		Expect(Eval(term.BigIntegerLit{Int: big.NewInt(-3)})).To(Equal(IntegerLit(-3)))
+		Expect(AlphaEquivalent(Eval(term.BigNaturalLit{Int: big.NewInt(3)}), Eval(term.NaturalLit(3)))).To(BeTrue())
+	})
+	It("Bytes/show", func() {
+		Expect(Eval(term.Apply(term.BytesShow, term.BytesLit{0x0f, 0xab}))).
+			To(Equal(PlainTextLit(`0x"0FAB"`)))
+	})
 	Describe("toMap", func() {
 		It("Evaluates with missing type and abstract value", func() {
 			Expect(Eval(term.ToMap{
@@ -49,3 +77,97 @@ var _ = Describe("Eval", func() {
 		})
 	})
 })
+
+var _ = Describe("EvalContext", func() {
+	// Natural/fold 100000000000 Integer Integer/negate +1
+	slowFold := term.Apply(term.NaturalFold,
+		term.NaturalLit(100000000000), term.Integer, term.IntegerNegate, term.IntegerLit(1))
+	It("Evaluates like Eval", func() {
+		Expect(EvalContext(context.Background(), term.Apply(term.NaturalEven, term.NaturalLit(2)))).
+			To(Equal(True))
+	})
+	It("Fails if the context is already done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := EvalContext(ctx, term.NaturalLit(1))
+		Expect(err).To(MatchError(context.Canceled))
+	})
+	It("Stops a long Natural/fold when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := EvalContext(ctx, slowFold)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+	It("Stops a long Natural/fold of a lambda when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := EvalContext(ctx, term.Apply(term.NaturalFold,
+			term.NaturalLit(100000000000), term.Natural,
+			term.NewLambda("x", term.Natural, term.NewVar("x")), term.NaturalLit(0)))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+	It("Returns functions which outlive the context", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		v, err := EvalContext(ctx, term.NewLambda("x", term.Natural, term.NewVar("x")))
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+		Expect(v.(lambda).Call(NaturalLit(3))).To(Equal(NaturalLit(3)))
+	})
+})
+
+var _ = Describe("EvalWithLimits", func() {
+	// Natural/fold n t (λ(x : t) → f x) zero
+	fold := func(n uint, t term.Term, f func(term.Term) term.Term, zero term.Term) term.Term {
+		return term.Apply(term.NaturalFold, term.NaturalLit(n), t,
+			term.NewLambda("x", t, f(term.NewVar("x"))), zero)
+	}
+	increment := fold(1000, term.Natural, func(x term.Term) term.Term {
+		return term.NaturalPlus(x, term.NaturalLit(1))
+	}, term.NaturalLit(0))
+	growList := fold(1000, term.Apply(term.List, term.Natural), func(x term.Term) term.Term {
+		return term.ListAppend(x, term.NonEmptyList{term.NaturalLit(1)})
+	}, term.EmptyList{Type: term.Apply(term.List, term.Natural)})
+	growText := fold(1000, term.Text, func(x term.Term) term.Term {
+		return term.TextAppend(x, term.PlainText("a"))
+	}, term.PlainText(""))
+	var deep term.Term = term.NaturalLit(0)
+	for i := 0; i < 1000; i++ {
+		deep = term.NaturalPlus(term.NaturalLit(1), deep)
+	}
+	DescribeTable("Exceeding limits",
+		func(t term.Term, limits Limits, expected Limit) {
+			_, err := EvalWithLimits(context.Background(), t, limits)
+			Expect(err).To(Equal(&LimitError{Limit: expected, Max: 100}))
+		},
+		Entry("steps", increment, Limits{MaxSteps: 100}, StepLimit),
+		Entry("list length", growList, Limits{MaxListLength: 100}, ListLengthLimit),
+		Entry("text length", growText, Limits{MaxTextLength: 100}, TextLengthLimit),
+		Entry("depth", deep, Limits{MaxDepth: 100}, DepthLimit),
+	)
+	It("Evaluates within limits", func() {
+		limits := Limits{MaxSteps: 10000, MaxListLength: 1000, MaxTextLength: 1000, MaxDepth: 10000}
+		Expect(EvalWithLimits(context.Background(), increment, limits)).To(Equal(NaturalLit(1000)))
+		Expect(EvalWithLimits(context.Background(), deep, limits)).To(Equal(NaturalLit(1000)))
+	})
+	It("Limits evaluation needed to normalize a lambda", func() {
+		_, err := NormalizeWithLimits(context.Background(),
+			term.NewLambda("y", term.Natural, increment), Limits{MaxSteps: 100})
+		Expect(err).To(Equal(&LimitError{Limit: StepLimit, Max: 100}))
+	})
+	It("Limits the depth of quoting and comparing values", func() {
+		var v Value = NaturalLit(0)
+		for i := 0; i < 1000; i++ {
+			v = NonEmptyList{v}
+		}
+		limited := func(f func(*evaluator)) (err error) {
+			ev := newEvaluator(context.Background(), Limits{MaxDepth: 100})
+			defer ev.finish(&err)
+			f(ev)
+			return nil
+		}
+		Expect(limited(func(ev *evaluator) { ev.quote(v) })).
+			To(Equal(&LimitError{Limit: DepthLimit, Max: 100}))
+		Expect(limited(func(ev *evaluator) { ev.alphaEquivalent(v, v) })).
+			To(Equal(&LimitError{Limit: DepthLimit, Max: 100}))
+	})
+})
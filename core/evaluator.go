@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// checkInterval is how many evaluation steps are taken between
+// checks for cancellation.
+const checkInterval = 1024
+
+// Limits bounds the resources used by evaluation.  A zero field means
+// no limit.
+type Limits struct {
+	// MaxSteps is the maximum number of reduction steps.  Each
+	// application of a lambda, each let binding and each iteration
+	// of Natural/fold counts as one step.
+	MaxSteps int
+	// MaxListLength is the maximum length of any List value.
+	MaxListLength int
+	// MaxTextLength is the maximum length in bytes of the literal
+	// parts of any Text value.
+	MaxTextLength int
+	// MaxDepth is the maximum recursion depth of evaluation and
+	// typechecking.
+	MaxDepth int
+}
+
+// A Limit identifies one of the fields of Limits.
+type Limit int
+
+// The limits which can be exceeded.
+const (
+	StepLimit Limit = iota
+	ListLengthLimit
+	TextLengthLimit
+	DepthLimit
+)
+
+func (l Limit) String() string {
+	switch l {
+	case StepLimit:
+		return "steps"
+	case ListLengthLimit:
+		return "list length"
+	case TextLengthLimit:
+		return "text length"
+	case DepthLimit:
+		return "depth"
+	}
+	return fmt.Sprintf("Limit(%d)", int(l))
+}
+
+// A LimitError is returned when evaluation or typechecking exceeds
+// one of its Limits.
+type LimitError struct {
+	Limit Limit
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("evaluation exceeded the maximum %s of %d", e.Limit, e.Max)
+}
+
+// An evaluator holds the state of a single call to EvalContext() or
+// TypeOfContext().  Values produced by an evaluator capture it, so
+// that evaluation which happens lazily (such as when a lambda is
+// called) is also checked for cancellation and limits.
+//
+// A nil *evaluator is valid, and never interrupts evaluation; it is
+// used by Eval() and TypeOf().
+type evaluator struct {
+	ctx    context.Context
+	limits Limits
+	ticks  int
+	steps  int
+	depth  int
+	// done is set when the call which created the evaluator returns,
+	// after which Values which captured it are no longer interrupted
+	done bool
+}
+
+// interrupted is the panic value which unwinds an interrupted
+// evaluation back to EvalContext() or TypeOfContext().
+type interrupted struct{ err error }
+
+func newEvaluator(ctx context.Context, limits Limits) *evaluator {
+	return &evaluator{ctx: ctx, limits: limits}
+}
+
+func (ev *evaluator) active() bool { return ev != nil && !ev.done }
+
+// check is called at each evaluation step.  Every checkInterval
+// steps, it panics with interrupted if the evaluator's context is
+// done.
+func (ev *evaluator) check() {
+	if !ev.active() {
+		return
+	}
+	ev.ticks++
+	if ev.ticks%checkInterval != 0 {
+		return
+	}
+	if err := ev.ctx.Err(); err != nil {
+		panic(interrupted{err})
+	}
+}
+
+// exceeded panics with interrupted if n is over the given limit.
+func (ev *evaluator) exceeded(limit Limit, max, n int) {
+	if max > 0 && n > max {
+		panic(interrupted{&LimitError{Limit: limit, Max: max}})
+	}
+}
+
+// enter is called on entry to each level of evaluation,
+// typechecking, quoting or comparison, and leave on exit.
+func (ev *evaluator) enter() {
+	if !ev.active() {
+		return
+	}
+	ev.check()
+	ev.depth++
+	ev.exceeded(DepthLimit, ev.limits.MaxDepth, ev.depth)
+}
+
+func (ev *evaluator) leave() {
+	if ev.active() {
+		ev.depth--
+	}
+}
+
+// reduce counts one reduction step.
+func (ev *evaluator) reduce() {
+	if !ev.active() {
+		return
+	}
+	ev.check()
+	ev.steps++
+	ev.exceeded(StepLimit, ev.limits.MaxSteps, ev.steps)
+}
+
+// limitSize checks that v is not a List or Text which is too big, and
+// returns it.
+func (ev *evaluator) limitSize(v Value) Value {
+	if !ev.active() {
+		return v
+	}
+	switch v := v.(type) {
+	case NonEmptyList:
+		ev.exceeded(ListLengthLimit, ev.limits.MaxListLength, len(v))
+	case PlainTextLit:
+		ev.exceeded(TextLengthLimit, ev.limits.MaxTextLength, len(v))
+	case interpolatedText:
+		n := len(v.Suffix)
+		for _, c := range v.Chunks {
+			n += len(c.Prefix)
+		}
+		ev.exceeded(TextLengthLimit, ev.limits.MaxTextLength, n)
+	}
+	return v
+}
+
+// finish marks ev as done, and recovers from an interrupted panic by
+// storing its error in *err.  It must be deferred.
+func (ev *evaluator) finish(err *error) {
+	ev.done = true
+	if r := recover(); r != nil {
+		i, ok := r.(interrupted)
+		if !ok {
+			panic(r)
+		}
+		*err = i.err
+	}
+}
+
+func (ev *evaluator) evalTerm(t term.Term) Value {
+	return ev.eval(t, env{})
+}
+
+// EvalContext is like Eval, but stops evaluating and returns ctx's
+// error if ctx is done before evaluation finishes.
+func EvalContext(ctx context.Context, t term.Term) (Value, error) {
+	return EvalWithLimits(ctx, t, Limits{})
+}
+
+// EvalWithLimits is like EvalContext, but also returns a *LimitError
+// if evaluation exceeds any of limits.  The limits do not apply to
+// any evaluation which happens after EvalWithLimits returns, such as
+// when a returned lambda is called.
+func EvalWithLimits(ctx context.Context, t term.Term, limits Limits) (v Value, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ev := newEvaluator(ctx, limits)
+	defer ev.finish(&err)
+	return ev.evalTerm(t), nil
+}
+
+// TypeOfContext is like TypeOf, but stops typechecking and returns
+// ctx's error if ctx is done before typechecking finishes.
+func TypeOfContext(ctx context.Context, t term.Term) (Value, error) {
+	return TypeOfWithLimits(ctx, t, Limits{})
+}
+
+// TypeOfWithLimits is like TypeOfContext, but also returns a
+// *LimitError if typechecking, or the evaluation which it performs,
+// exceeds any of limits.
+func TypeOfWithLimits(ctx context.Context, t term.Term, limits Limits) (v Value, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ev := newEvaluator(ctx, limits)
+	defer ev.finish(&err)
+	return ev.typeWith(typeContext{}, t)
+}
+
+// NormalizeWithLimits evaluates t and quotes the result, returning t
+// in beta-normal form.  Unlike calling Quote() on the result of
+// EvalWithLimits(), any evaluation needed to quote the result is
+// also subject to limits.
+func NormalizeWithLimits(ctx context.Context, t term.Term, limits Limits) (n term.Term, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ev := newEvaluator(ctx, limits)
+	defer ev.finish(&err)
+	return ev.quote(ev.evalTerm(t)), nil
+}
@@ -0,0 +1,1037 @@
+// The explanations in this file are adapted from those in
+// dhall-haskell's src/Dhall/TypeCheck.hs, Copyright (c) 2017 Gabriel
+// Gonzalez, and used under its BSD 3-Clause license; see NOTICE.
+
+package core
+
+// Explain returns the error message of e, followed by a long-form
+// explanation of the kind of error, with examples of what causes it
+// and how to fix it.
+func (e *TypeError) Explain() string {
+	explanation, ok := explanations[e.Code]
+	if !ok {
+		return e.Error()
+	}
+	return e.Error() + "\n\n" + explanation
+}
+
+// explanations is the catalogue of long-form explanations of each
+// TypeErrorCode.
+var explanations = map[TypeErrorCode]string{
+	UnboundVariable: `Explanation: Expressions can only reference previously introduced (i.e. "bound")
+variables that are still "in scope".
+
+For example, the following valid expressions introduce a "bound" variable named
+❰x❱:
+
+    ┌─────────────────┐
+    │ λ(x : Bool) → x │  Anonymous functions introduce "bound" variables
+    └─────────────────┘
+        ⇧
+        This is the bound variable
+
+    ┌────────────────┐
+    │ let x = 1 in x │  ❰let❱ expressions introduce "bound" variables
+    └────────────────┘
+          ⇧
+          This is the bound variable
+
+However, the following expressions are not valid because they all reference a
+variable that has not been introduced yet (i.e. an "unbound" variable):
+
+    ┌─────────────────┐
+    │ λ(x : Bool) → y │  The variable ❰y❱ hasn't been introduced yet
+    └─────────────────┘
+                    ⇧
+                    This is the unbound variable
+
+    ┌──────────────────────────┐
+    │ (let x = True in x) && x │  ❰x❱ is undefined outside the parentheses
+    └──────────────────────────┘
+                             ⇧
+                             This is the unbound variable
+
+You might have:
+
+● misspelled a variable name
+
+● forgotten to bind the variable with ❰let❱ or a ❰λ❱
+
+● used a variable index (such as ❰x@1❱) greater than the number of enclosing
+  bindings of that name`,
+
+	AnnotationMismatch: `Explanation: You can annotate an expression with its type or kind using the
+❰:❱ symbol, like this:
+
+    ┌───────┐
+    │ x : t │  ❰x❱ is an expression and ❰t❱ is the annotated type or kind of ❰x❱
+    └───────┘
+
+The type checker verifies that the expression's type or kind matches the
+provided annotation
+
+For example, all of the following are valid annotations that the type checker
+accepts:
+
+    ┌─────────────┐
+    │ 1 : Natural │  ❰1❱ is an expression that has type ❰Natural❱, so the type
+    └─────────────┘  checker accepts the annotation
+
+    ┌───────────────────────┐
+    │ Natural/even 2 : Bool │  ❰Natural/even 2❱ has type ❰Bool❱, so the type
+    └───────────────────────┘  checker accepts the annotation
+
+However, the following annotations are not valid and the type checker will
+reject them:
+
+    ┌──────────┐
+    │ 1 : Text │  The type checker rejects this because ❰1❱ does not have type
+    └──────────┘  ❰Text❱
+
+    ┌─────────────┐
+    │ List : Type │  ❰List❱ does not have kind ❰Type❱
+    └─────────────┘
+
+You or the interpreter annotated an expression with a type or kind that does
+not match the type or kind the expression actually has.`,
+
+	AlternativeAnnotationMismatch: `Explanation: The annotation of an alternative of a union type does not match
+the annotation which was expected for it.
+
+Every alternative of a union type is either empty, or annotated with a type or
+a kind, like this:
+
+    ┌───────────────────────────────────┐
+    │ < Left : Natural | Right : Bool > │  Both alternatives are annotated
+    └───────────────────────────────────┘
+
+    ┌─────────────────────────┐
+    │ < Empty | Full : Text > │  ❰Empty❱ stores no value
+    └─────────────────────────┘
+
+Check the annotations of the union's alternatives against the type you meant.`,
+
+	AssertionFailed: `Explanation: The expression ❰assert : x === y❱ only type-checks if ❰x❱ and ❰y❱
+are equivalent; that is, if they have the same normal form.
+
+For example, the following assertion is valid:
+
+    ┌──────────────────────────────────┐
+    │ assert : Natural/even 2 === True │  Both sides normalize to ❰True❱
+    └──────────────────────────────────┘
+
+... but the following assertion is not valid:
+
+    ┌──────────────────┐
+    │ assert : 1 === 2 │  ❰1❱ and ❰2❱ are not equivalent
+    └──────────────────┘
+
+The error message above shows the normal forms of both sides of the failed
+assertion.  Either the assertion is wrong, or the expression it tests does not
+do what you expected.`,
+
+	CantAccess: `Explanation: You can only access fields on records or constructors of union
+types, like this:
+
+    ┌─────────────────────────────────┐
+    │ { foo = True, bar = "ABC" }.foo │  This is valid ...
+    └─────────────────────────────────┘
+
+    ┌───────────────────────────────────────────┐
+    │ λ(r : { foo : Bool, bar : Text }) → r.foo │  ... and so is this
+    └───────────────────────────────────────────┘
+
+    ┌─────────────────────────────────┐
+    │ < Left : Natural | Right >.Left │  ... and so is this
+    └─────────────────────────────────┘
+
+... but you cannot access fields on anything else.  For example, the following
+expression is not valid:
+
+    ┌──────────┐
+    │ Bool.foo │  ❰Bool❱ is neither a record nor a union type
+    └──────────┘
+
+You tried to access a field of an expression which is neither a record value
+nor a union type.`,
+
+	CantBoolOp: `Explanation: The boolean operators ❰&&❱, ❰||❱, ❰==❱ and ❰!=❱ only work on values
+of type ❰Bool❱.
+
+For example, the following expressions are valid:
+
+    ┌───────────────┐
+    │ True && False │
+    └───────────────┘
+
+    ┌───────────────────────────────────┐
+    │ λ(x : Bool) → x || Natural/even 2 │
+    └───────────────────────────────────┘
+
+... but the following expression is not valid:
+
+    ┌────────┐
+    │ 1 == 1 │  ❰==❱ does not work on ❰Natural❱ numbers
+    └────────┘
+
+To compare ❰Natural❱ numbers, use functions such as ❰Natural/equal❱ from the
+Prelude.`,
+
+	CantInterpolate: `Explanation: You can only interpolate ❰Text❱ into a ❰Text❱ literal.
+
+For example, these are valid uses of string interpolation:
+
+    ┌──────────────────┐
+    │ "ABC${"DEF"}GHI" │
+    └──────────────────┘
+
+    ┌────────────────────────────┐
+    │ λ(x : Text) → "ABC${x}GHI" │
+    └────────────────────────────┘
+
+... but you cannot interpolate any other type of value:
+
+    ┌──────────────┐
+    │ "ABC${1}GHI" │  ❰1❱ is a ❰Natural❱, not ❰Text❱
+    └──────────────┘
+
+Convert the value to ❰Text❱ first, with a function such as ❰Natural/show❱:
+
+    ┌───────────────────────────┐
+    │ "ABC${Natural/show 1}GHI" │
+    └───────────────────────────┘`,
+
+	CantListAppend: `Explanation: The ❰#❱ operator concatenates two ❰List❱s, like this:
+
+    ┌─────────────────┐
+    │ [1, 2] # [3, 4] │
+    └─────────────────┘
+
+... but both arguments must be ❰List❱s.  The following is not valid:
+
+    ┌────────────┐
+    │ [1, 2] # 3 │  ❰3❱ is not a ❰List❱
+    └────────────┘
+
+If you meant to add a single element, wrap it in a ❰List❱ literal:
+
+    ┌──────────────┐
+    │ [1, 2] # [3] │
+    └──────────────┘`,
+
+	CantNaturalOp: `Explanation: The arithmetic operators ❰+❱ and ❰*❱ only work on values of type
+❰Natural❱.
+
+For example, the following expressions are valid:
+
+    ┌───────┐
+    │ 2 + 3 │
+    └───────┘
+
+    ┌────────────────────────┐
+    │ λ(x : Natural) → x * 2 │
+    └────────────────────────┘
+
+... but the following expressions are not valid:
+
+    ┌─────────┐
+    │ +2 + +3 │  ❰+2❱ and ❰+3❱ are ❰Integer❱s, not ❰Natural❱s
+    └─────────┘
+
+    ┌───────────┐
+    │ 1.0 * 2.0 │  ❰Double❱s do not support arithmetic
+    └───────────┘
+
+Note that ❰+❱ is also not used to concatenate ❰Text❱; use ❰++❱ for that.`,
+
+	CantProject: `Explanation: You can only project fields out of records, like this:
+
+    ┌───────────────────────────────────────────────────┐
+    │ { foo = True, bar = "ABC", baz = 1 }.{ foo, bar } │
+    └───────────────────────────────────────────────────┘
+
+... but you cannot project fields out of anything else.  For example, the
+following expression is not valid:
+
+    ┌──────────────┐
+    │ True.{ foo } │  ❰True❱ is not a record
+    └──────────────┘
+
+You tried to project fields from an expression which is not a record.`,
+
+	CantProjectByExpression: `Explanation: You can project fields out of a record using a record type as the
+selector, like this:
+
+    ┌──────────────────────────────────────────────┐
+    │ { foo = True, bar = "ABC" }.({ foo : Bool }) │
+    └──────────────────────────────────────────────┘
+
+... but the selector must be a record type.  The following is not valid:
+
+    ┌────────────────────────────────────┐
+    │ { foo = True, bar = "ABC" }.(Bool) │  ❰Bool❱ is not a record type
+    └────────────────────────────────────┘
+
+The expression in parentheses after the ❰.❱ did not evaluate to a record type.`,
+
+	CantTextAppend: `Explanation: The ❰++❱ operator concatenates two pieces of ❰Text❱, like this:
+
+    ┌────────────────┐
+    │ "ABC" ++ "DEF" │
+    └────────────────┘
+
+... but both arguments must be ❰Text❱.  The following is not valid:
+
+    ┌────────────┐
+    │ "ABC" ++ 1 │  ❰1❱ is a ❰Natural❱, not ❰Text❱
+    └────────────┘
+
+Convert the value to ❰Text❱ first, with a function such as ❰Natural/show❱, or
+use ❰#❱ if you meant to concatenate ❰List❱s.`,
+
+	CombineTypesRequiresRecordType: `Explanation: The ❰⩓❱ (or ❰//\\❱) operator combines two record types, like this:
+
+    ┌────────────────────────────────────┐
+    │ { foo : Bool } ⩓ { bar : Natural } │
+    └────────────────────────────────────┘
+
+... but both arguments must be record types.  The following is not valid:
+
+    ┌──────────────────────────┐
+    │ { foo : Bool } ⩓ Natural │  ❰Natural❱ is not a record type
+    └──────────────────────────┘
+
+If you meant to combine record values rather than record types, use the ❰∧❱
+(or ❰/\❱) operator instead.`,
+
+	DisallowedHandlerType: `Explanation: The output type of a ❰merge❱ handler cannot depend on the value
+of its input.
+
+For example, this handler is valid, because it always returns a ❰Bool❱:
+
+    ┌──────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n } │
+    │   (< Left : Natural >.Left 1)                    │
+    └──────────────────────────────────────────────────┘
+
+... but a handler whose output type refers to its argument is not:
+
+    ┌─────────────────────────────────────────────┐
+    │ merge { Left = λ(t : Type) → λ(x : t) → x } │
+    │   (< Left : Type >.Left Bool)               │
+    └─────────────────────────────────────────────┘
+
+Every handler must return a value of a single, fixed type.`,
+
+	DuplicateProjectedField: `Explanation: A projection cannot select the same field more than once.
+
+For example, this projection is valid:
+
+    ┌──────────────────────────────────────┐
+    │ { foo = True, bar = 1 }.{ foo, bar } │
+    └──────────────────────────────────────┘
+
+... but this one is not:
+
+    ┌──────────────────────────────────────┐
+    │ { foo = True, bar = 1 }.{ foo, foo } │  ❰foo❱ is selected twice
+    └──────────────────────────────────────┘
+
+Remove the repeated field from the projection.`,
+
+	EquivalenceTypeMismatch: `Explanation: The ❰===❱ operator compares two expressions of the same type.
+
+For example, this equivalence is valid, since both sides are ❰Natural❱s:
+
+    ┌─────────┐
+    │ 1 === 2 │
+    └─────────┘
+
+... but the following is not, because the two sides have different types:
+
+    ┌────────────┐
+    │ 1 === True │  ❰1❱ is a ❰Natural❱ but ❰True❱ is a ❰Bool❱
+    └────────────┘
+
+Check that both sides of the ❰===❱ are the expressions you meant to compare.`,
+
+	HandlerInputTypeMismatch: `Explanation: Each handler in a ❰merge❱ must accept the type of value stored in
+the alternative it handles.
+
+For example, this is valid:
+
+    ┌─────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n, │
+    │         Right = λ(b : Bool) → b }               │
+    │   (< Left : Natural | Right : Bool >.Left 1)    │
+    └─────────────────────────────────────────────────┘
+
+... but the following is not, because the ❰Left❱ alternative stores a
+❰Natural❱, while its handler expects ❰Text❱:
+
+    ┌─────────────────────────────────────┐
+    │ merge { Left = λ(t : Text) → True } │
+    │   (< Left : Natural >.Left 1)       │
+    └─────────────────────────────────────┘
+
+Change the handler's input type to match the type of the alternative.`,
+
+	HandlerNotAFunction: `Explanation: Each handler in a ❰merge❱ for an alternative which stores a value
+must be a function, which receives that value.
+
+For example, this is valid:
+
+    ┌─────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → n } │
+    │   (< Left : Natural >.Left 1)       │
+    └─────────────────────────────────────┘
+
+... but the following is not:
+
+    ┌───────────────────────────────┐
+    │ merge { Left = 0 }            │  ❰0❱ is not a function
+    │   (< Left : Natural >.Left 1) │
+    └───────────────────────────────┘
+
+Alternatives which store no value, such as ❰< Empty | Full : Natural >.Empty❱,
+are handled by a plain value instead.`,
+
+	HandlerOutputTypeMismatch: `Explanation: Every handler in a ❰merge❱ must return a value of the same type,
+since that is the type of the whole ❰merge❱ expression.
+
+For example, this is valid, because both handlers return ❰Bool❱:
+
+    ┌──────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n,  │
+    │         Right = λ(b : Bool) → b }                │
+    │   (< Left : Natural | Right : Bool >.Right True) │
+    └──────────────────────────────────────────────────┘
+
+... but the following is not, because one handler returns a ❰Natural❱ and the
+other a ❰Bool❱:
+
+    ┌──────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → n,               │
+    │         Right = λ(b : Bool) → b }                │
+    │   (< Left : Natural | Right : Bool >.Right True) │
+    └──────────────────────────────────────────────────┘
+
+Make every handler return the same type.`,
+
+	HeterogenousRecordToMap: `Explanation: ❰toMap❱ converts a record into a ❰List❱ of key-value pairs, so
+every field of the record must have the same type.
+
+For example, this is valid:
+
+    ┌────────────────────────────┐
+    │ toMap { foo = 1, bar = 2 } │  Every field is a ❰Natural❱
+    └────────────────────────────┘
+
+... but the following is not:
+
+    ┌────────────────────────────────┐
+    │ toMap { foo = 1, bar = "ABC" } │  ❰foo❱ and ❰bar❱ have different types
+    └────────────────────────────────┘
+
+Convert the fields to a common type first, such as ❰Text❱.`,
+
+	IfBranchMismatch: `Explanation: Every ❰if❱ expression has a ❰then❱ and ❰else❱ branch, each of
+which is an expression:
+
+                   Expression for ❰then❱ branch
+                   ⇩
+    ┌────────────────────────────────┐
+    │ if True then "Hello, world!"   │
+    │         else "Goodbye, world!" │
+    └────────────────────────────────┘
+                   ⇧
+                   Expression for ❰else❱ branch
+
+These two expressions must have the same type.  For example, the following
+❰if❱ expression is not valid:
+
+    ┌───────────────────────────┐
+    │ if True then 1 else "ABC" │  ❰1❱ is a ❰Natural❱, but ❰"ABC"❱ is ❰Text❱
+    └───────────────────────────┘
+
+If you need the branches to return different types, return a union instead.`,
+
+	IfBranchMustBeTerm: `Explanation: Every branch of an ❰if❱ expression must be a term; that is, a
+value whose type has kind ❰Type❱.
+
+For example, the following ❰if❱ expression is valid:
+
+    ┌───────────────────────┐
+    │ if True then 1 else 2 │
+    └───────────────────────┘
+
+... but the following is not, because the branches are types, not terms:
+
+    ┌────────────────────────────────┐
+    │ if True then Natural else Bool │
+    └────────────────────────────────┘
+
+Dhall does not allow choosing between types with ❰if❱.`,
+
+	IncomparableExpression: `Explanation: The ❰===❱ operator (and ❰assert❱) can only compare terms; that is,
+values whose type has kind ❰Type❱.
+
+For example, these equivalences are valid:
+
+    ┌─────────┐
+    │ 2 === 2 │
+    └─────────┘
+
+    ┌───────────────────────────────────────────────┐
+    │ (λ(x : Natural) → x) === (λ(y : Natural) → y) │
+    └───────────────────────────────────────────────┘
+
+... but the following are not, because one side is a type:
+
+    ┌─────────────────────┐
+    │ Natural === Natural │  ❰Natural❱ is a type, not a term
+    └─────────────────────┘
+
+    ┌────────────┐
+    │ 2 === Type │  ❰Type❱ is a kind, not a term
+    └────────────┘`,
+
+	InvalidAlternativeType: `Explanation: Every alternative of a union type must be annotated with a type
+or a kind, like this:
+
+    ┌───────────────────────────────────┐
+    │ < Left : Natural | Right : Bool > │  ❰Natural❱ and ❰Bool❱ are types
+    └───────────────────────────────────┘
+
+... but you cannot annotate an alternative with a term:
+
+    ┌──────────────────────┐
+    │ < Left : 1 | Right > │  ❰1❱ is a term, not a type
+    └──────────────────────┘
+
+You may have written ❰=❱ where you meant ❰:❱, or the other way around.`,
+
+	InvalidFieldType: `Explanation: Every field of a record type must be annotated with a type or a
+kind, like this:
+
+    ┌───────────────────────────────┐
+    │ { foo : Natural, bar : Text } │  ❰Natural❱ and ❰Text❱ are types
+    └───────────────────────────────┘
+
+... but you cannot annotate a field with a term:
+
+    ┌────────────────────────────┐
+    │ { foo : Natural, bar : 1 } │  ❰1❱ is a term, not a type
+    └────────────────────────────┘
+
+You may have written ❰:❱ where you meant ❰=❱.  Record values use ❰=❱, as in
+❰{ bar = 1 }❱, and record types use ❰:❱.`,
+
+	InvalidInputType: `Explanation: A function can accept an input term, type or kind, so the input
+annotation of a ❰λ❱ or a function type must be a type, a kind or a sort.
+
+For example, these are valid:
+
+    ┌────────────────────┐
+    │ λ(x : Natural) → x │  ❰Natural❱ is a type
+    └────────────────────┘
+
+    ┌─────────────────┐
+    │ λ(a : Type) → a │  ❰Type❱ is a kind
+    └─────────────────┘
+
+... but the following is not:
+
+    ┌──────────────┐
+    │ λ(x : 1) → x │  ❰1❱ is a term, not a type
+    └──────────────┘
+
+The annotation after the ❰:❱ must describe the kind of input the function
+accepts, not be an example of one.`,
+
+	InvalidListType: `Explanation: ❰List❱ is a function which takes the type of the list's elements,
+and returns the type of the list.  An empty list must be annotated with such a
+list type, like this:
+
+    ┌───────────────────┐
+    │ [] : List Natural │
+    └───────────────────┘
+
+... but the following are not valid:
+
+    ┌──────────────┐
+    │ [] : Natural │  ❰Natural❱ is not a ❰List❱ type
+    └──────────────┘
+
+    ┌─────────────┐
+    │ [] : List 3 │  ❰3❱ is not a type
+    └─────────────┘
+
+The elements of a ❰List❱ must also be terms; you cannot have a ❰List❱ of
+types.`,
+
+	InvalidOutputType: `Explanation: The output of a function type must be a type, a kind or a sort.
+
+For example, these function types are valid:
+
+    ┌────────────────┐
+    │ Natural → Bool │  ❰Bool❱ is a type
+    └────────────────┘
+
+    ┌─────────────┐
+    │ Type → Type │  ❰Type❱ is a kind
+    └─────────────┘
+
+... but the following is not:
+
+    ┌─────────────┐
+    │ Natural → 1 │  ❰1❱ is a term, not a type
+    └─────────────┘
+
+The expression after the ❰→❱ of a function type must describe what the
+function returns, not be an example of it.`,
+
+	InvalidPredicate: `Explanation: Every ❰if❱ expression begins with a predicate which must have type
+❰Bool❱.
+
+For example, these are valid ❰if❱ expressions:
+
+    ┌──────────────────────────────┐
+    │ if True then "Yes" else "No" │
+    └──────────────────────────────┘
+         ⇧
+         Predicate
+
+    ┌─────────────────────────────────────────┐
+    │ λ(x : Bool) → if x then False else True │
+    └─────────────────────────────────────────┘
+                       ⇧
+                       Predicate
+
+... but the following is not, because the predicate is a ❰Natural❱:
+
+    ┌───────────────────────────┐
+    │ if 0 then "Yes" else "No" │
+    └───────────────────────────┘
+
+Dhall has no "truthy" values; use a function such as ❰Natural/isZero❱ to
+produce a ❰Bool❱.`,
+
+	InvalidSome: `Explanation: The ❰Some❱ constructor expects an argument that is a term; that
+is, a value whose type has kind ❰Type❱.
+
+For example, these are valid uses of ❰Some❱:
+
+    ┌────────┐
+    │ Some 1 │
+    └────────┘
+
+    ┌───────────────┐
+    │ Some [ True ] │
+    └───────────────┘
+
+... but the following is not, because ❰Natural❱ is a type, not a term:
+
+    ┌──────────────┐
+    │ Some Natural │
+    └──────────────┘
+
+If you meant the type of optional ❰Natural❱s, write ❰Optional Natural❱.`,
+
+	InvalidToMapRecordKind: `Explanation: ❰toMap❱ converts a record value into a ❰List❱ of key-value pairs,
+so its argument must be a record of terms.
+
+For example, this is valid:
+
+    ┌────────────────────────────┐
+    │ toMap { foo = 1, bar = 2 } │
+    └────────────────────────────┘
+
+... but the following is not, because the fields are types:
+
+    ┌─────────────────────────────────────┐
+    │ toMap { foo = Natural, bar = Bool } │
+    └─────────────────────────────────────┘`,
+
+	InvalidToMapType: `Explanation: An empty ❰toMap❱ must be annotated with the type of the ❰List❱ it
+produces, which must have the form:
+
+    ┌──────────────────────────────────────┐
+    │ List { mapKey : Text, mapValue : T } │
+    └──────────────────────────────────────┘
+
+For example, this is valid:
+
+    ┌─────────────────────────────────────────────────────┐
+    │ toMap {=} : List { mapKey : Text, mapValue : Bool } │
+    └─────────────────────────────────────────────────────┘
+
+... but the following is not, because the annotation is not a ❰List❱ of
+key-value records:
+
+    ┌──────────────────────────┐
+    │ toMap {=} : List Natural │
+    └──────────────────────────┘`,
+
+	ListAppendMismatch: `Explanation: The ❰#❱ operator concatenates two ❰List❱s with the same element
+type, like this:
+
+    ┌─────────────────┐
+    │ [1, 2] # [3, 4] │
+    └─────────────────┘
+
+... but the following is not valid, because the ❰List❱s have different
+element types:
+
+    ┌────────────────────────┐
+    │ [1, 2] # [True, False] │  ❰Natural❱ and ❰Bool❱ elements
+    └────────────────────────┘
+
+Convert the elements of one ❰List❱ to the type of the other first, for example
+with ❰List/map❱ from the Prelude.`,
+
+	MapTypeMismatch: `Explanation: A ❰toMap❱ expression may be annotated with the type of the ❰List❱
+it produces, and the annotation must match the type of the record's fields.
+
+For example, this is valid:
+
+    ┌────────────────────────────────────────────────────────────────┐
+    │ toMap { foo = 1 } : List { mapKey : Text, mapValue : Natural } │
+    └────────────────────────────────────────────────────────────────┘
+
+... but the following is not, because the field ❰foo❱ is a ❰Natural❱ but the
+annotation says ❰Bool❱:
+
+    ┌─────────────────────────────────────────────────────────────┐
+    │ toMap { foo = 1 } : List { mapKey : Text, mapValue : Bool } │
+    └─────────────────────────────────────────────────────────────┘`,
+
+	MismatchedListElements: `Explanation: Every element in a ❰List❱ must have the same type.
+
+For example, this is a valid ❰List❱:
+
+    ┌───────────┐
+    │ [1, 2, 3] │  Every element in this ❰List❱ is a ❰Natural❱
+    └───────────┘
+
+... but this is not a valid ❰List❱:
+
+    ┌───────────────┐
+    │ [1, "ABC", 3] │  The first and second element have different types
+    └───────────────┘
+
+If you need a list of values of different types, wrap them in a union type
+first:
+
+    ┌───────────────────────────────────────────────────┐
+    │ let Element = < N : Natural | T : Text >          │
+    │ in  [ Element.N 1, Element.T "ABC", Element.N 3 ] │
+    └───────────────────────────────────────────────────┘`,
+
+	MissingConstructor: `Explanation: You can access constructors of union types, like this:
+
+    ┌────────────────────────────────────────┐
+    │ < Left : Natural | Right : Bool >.Left │  This is valid ...
+    └────────────────────────────────────────┘
+
+... but you can only access constructors if they match a union alternative of
+the same name.  For example, the following expression is not valid:
+
+    ┌──────────────────────────────────────┐
+    │ < Left : Natural | Right : Bool >.Up │  Invalid: the union has no ❰Up❱
+    └──────────────────────────────────────┘     alternative
+
+You might have misspelled the constructor name, or be using a different
+version of the union type than you expected.`,
+
+	MissingField: `Explanation: You can only access fields on records, like this:
+
+    ┌─────────────────────────────────┐
+    │ { foo = True, bar = "ABC" }.foo │  This is valid ...
+    └─────────────────────────────────┘
+
+... but you can only access fields if they are present.  For example, the
+following expression is not valid:
+
+    ┌─────────────────────────────────┐
+    │ { foo = True, bar = "ABC" }.qux │  Invalid: the record has no ❰qux❱
+    └─────────────────────────────────┘  field
+
+The same applies to projections such as ❰r.{ foo, qux }❱: every projected field
+must be present in the record.
+
+You might have misspelled the field name, or the record might come from an
+import or function argument whose type is not what you expected.`,
+
+	MissingHandler: `Explanation: A ❰merge❱ expression must have a handler for every alternative of
+the union it consumes.
+
+For example, this is valid:
+
+    ┌─────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n, │
+    │         Right = λ(b : Bool) → b }               │
+    │   (< Left : Natural | Right : Bool >.Left 1)    │
+    └─────────────────────────────────────────────────┘
+
+... but the following is not, because there is no handler for ❰Right❱:
+
+    ┌──────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n } │
+    │   (< Left : Natural | Right : Bool >.Left 1)     │
+    └──────────────────────────────────────────────────┘
+
+Even if the value being merged is never ❰Right❱, the handler is required.
+When merging an ❰Optional❱ value, the handlers are named ❰Some❱ and ❰None❱.`,
+
+	MissingMergeType: `Explanation: A ❰merge❱ of an empty union has no handlers from which to infer its
+result type, so it must be annotated with one, like this:
+
+    ┌───────────────────────────────────┐
+    │ λ(x : <>) → merge {=} x : Natural │
+    └───────────────────────────────────┘
+
+... but the following is not valid:
+
+    ┌─────────────────────────┐
+    │ λ(x : <>) → merge {=} x │  No annotation
+    └─────────────────────────┘`,
+
+	MissingToMapType: `Explanation: A ❰toMap❱ of an empty record has no fields from which to infer its
+result type, so it must be annotated with one, like this:
+
+    ┌─────────────────────────────────────────────────────┐
+    │ toMap {=} : List { mapKey : Text, mapValue : Bool } │
+    └─────────────────────────────────────────────────────┘
+
+... but the following is not valid:
+
+    ┌───────────┐
+    │ toMap {=} │  No annotation
+    └───────────┘`,
+
+	MustCombineARecord: `Explanation: The ❰∧❱ (or ❰/\❱) and ❰⫽❱ (or ❰//❱) operators combine two records,
+like this:
+
+    ┌───────────────────────────────┐
+    │ { foo = 1 } ∧ { bar = "ABC" } │
+    └───────────────────────────────┘
+
+    ┌───────────────────────────┐
+    │ { foo = 1 } ⫽ { foo = 2 } │
+    └───────────────────────────┘
+
+... but both arguments must be records.  The following is not valid:
+
+    ┌────────────────────┐
+    │ { foo = 1 } ∧ True │  ❰True❱ is not a record
+    └────────────────────┘
+
+The same applies to the record updated by a ❰with❱ expression.`,
+
+	MustMergeARecord: `Explanation: The first argument of ❰merge❱ must be a record of handlers, one
+for each alternative of the union being merged, like this:
+
+    ┌─────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n, │
+    │         Right = λ(b : Bool) → b }               │
+    │   (< Left : Natural | Right : Bool >.Left 1)    │
+    └─────────────────────────────────────────────────┘
+            ⇧
+            This must be a record
+
+... but the following is not valid:
+
+    ┌────────────────────────────────────────┐
+    │ merge True (< Left : Natural >.Left 1) │  ❰True❱ is not a record
+    └────────────────────────────────────────┘`,
+
+	MustMergeUnion: `Explanation: The second argument of ❰merge❱ must be a value of a union type or
+an ❰Optional❱ value, like this:
+
+    ┌─────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → n } │
+    │   (< Left : Natural >.Left 1)       │
+    └─────────────────────────────────────┘
+
+... but the following is not valid:
+
+    ┌───────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → n } 1 │  ❰1❱ is not a union value
+    └───────────────────────────────────────┘`,
+
+	NotAFunction: `Explanation: Expressions separated by whitespace denote function application,
+like this:
+
+    ┌─────┐
+    │ f x │  This denotes the function ❰f❱ applied to an argument named ❰x❱
+    └─────┘
+
+A function is a term that has type ❰a → b❱ for some ❰a❱ or ❰b❱.  For example,
+the following expressions are all functions because they have a function type:
+
+                        The function's input type is ❰Bool❱
+                        ⇩
+    ┌───────────────────────────────┐
+    │ λ(x : Bool) → x : Bool → Bool │  User-defined anonymous function
+    └───────────────────────────────┘
+                               ⇧
+                               The function's output type is ❰Bool❱
+
+    ┌───────────────────────────────┐
+    │ Natural/even : Natural → Bool │  Built-in function
+    └───────────────────────────────┘
+
+However, the following expressions are not functions, and cannot be applied:
+
+    ┌────────┐
+    │ True 1 │  ❰True❱ is a ❰Bool❱, not a function
+    └────────┘
+
+    ┌─────────────────┐
+    │ Natural Natural │  ❰Natural❱ is a type, not a function
+    └─────────────────┘
+
+You might have forgotten an operator or a comma between two expressions.`,
+
+	NotAnEquivalence: `Explanation: An ❰assert❱ must be annotated with an equivalence, written with the
+❰===❱ operator, like this:
+
+    ┌──────────────────────┐
+    │ assert : 1 + 1 === 2 │
+    └──────────────────────┘
+
+... but the following are not valid:
+
+    ┌───────────────┐
+    │ assert : True │  ❰True❱ is not an equivalence
+    └───────────────┘
+
+    ┌─────────────────────┐
+    │ assert : 1 + 1 == 2 │  ❰==❱ compares ❰Bool❱s; use ❰===❱
+    └─────────────────────┘`,
+
+	ProjectionTypeMismatch: `Explanation: When projecting fields out of a record using a record type as the
+selector, each field of the selector must have the same type as the
+corresponding field of the record.
+
+For example, this is valid:
+
+    ┌──────────────────────────────────────────┐
+    │ { foo = True, bar = 1 }.({ foo : Bool }) │
+    └──────────────────────────────────────────┘
+
+... but the following is not, because ❰foo❱ is a ❰Bool❱ but the selector says
+❰Natural❱:
+
+    ┌─────────────────────────────────────────────┐
+    │ { foo = True, bar = 1 }.({ foo : Natural }) │
+    └─────────────────────────────────────────────┘`,
+
+	ShowConstructorNotOnUnion: `Explanation: ❰showConstructor❱ returns the name of the alternative of a union
+value (or ❰"Some"❱ or ❰"None"❱ for an ❰Optional❱ value), so its argument must
+be one of those.
+
+For example, these are valid:
+
+    ┌─────────────────────────────────────────────┐
+    │ showConstructor (< Left : Natural >.Left 1) │
+    └─────────────────────────────────────────────┘
+
+    ┌──────────────────────────┐
+    │ showConstructor (Some 1) │
+    └──────────────────────────┘
+
+... but the following is not:
+
+    ┌───────────────────┐
+    │ showConstructor 1 │  ❰1❱ is not a union or ❰Optional❱ value
+    └───────────────────┘`,
+
+	TypeMismatch: `Explanation: Every function declares what type or kind of argument to accept.
+
+For example:
+
+    ┌───────────────────────────────┐
+    │ λ(x : Bool) → x : Bool → Bool │  This anonymous function only accepts
+    └───────────────────────────────┘  arguments that have type ❰Bool❱
+                        ⇧
+                        The function's input type
+
+    ┌───────────────────────────────┐
+    │ Natural/even : Natural → Bool │  This built-in function only accepts
+    └───────────────────────────────┘  arguments that have type ❰Natural❱
+                   ⇧
+                   The function's input type
+
+    ┌────────────────────┐
+    │ List : Type → Type │  This built-in function only accepts
+    └────────────────────┘  arguments that have kind ❰Type❱
+
+You cannot apply a function to the wrong type or kind of argument.  For example,
+the following expressions are not valid:
+
+    ┌──────────────────┐
+    │ Natural/even "1" │  ❰"1"❱ has type ❰Text❱, but ❰Natural/even❱ expects
+    └──────────────────┘  an argument of type ❰Natural❱
+
+    ┌────────┐
+    │ List 1 │  ❰1❱ has type ❰Natural❱, but ❰List❱ expects an argument
+    └────────┘  of kind ❰Type❱
+
+The error message above shows the type which the function expected, and the
+type of the argument it was given.`,
+
+	UnhandledTypeCase: `Explanation: The type checker met an expression which it does not know how to
+type check.  This is a bug in dhall-golang, not in your Dhall code; please
+report it, including the expression which caused it.
+
+If the expression contains unresolved imports, resolve them (for example, with
+the ❰imports❱ package) before type checking.`,
+
+	Untyped: `Explanation: There are four levels of expressions that form a hierarchy:
+
+● terms
+● types
+● kinds
+● sorts
+
+The following example illustrates this hierarchy:
+
+    ┌───────────────────────────────────┐
+    │ "ABC" : Text : Type : Kind : Sort │
+    └───────────────────────────────────┘
+       ⇧      ⇧      ⇧      ⇧      ⇧
+       term   type   kind   sort   ❰Sort❱ itself
+
+There is nothing above ❰Sort❱ in this hierarchy, so ❰Sort❱ has no type, kind or
+sort.  Any expression which needs the type of ❰Sort❱, such as ❰Sort : Sort❱ or
+❰λ(x : Sort) → x❱, is rejected.`,
+
+	UnusedHandler: `Explanation: A ❰merge❱ expression must have exactly one handler for each
+alternative of the union it consumes, and no others.
+
+For example, this is valid:
+
+    ┌──────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n } │
+    │   (< Left : Natural >.Left 1)                    │
+    └──────────────────────────────────────────────────┘
+
+... but the following is not, because the union has no ❰Right❱ alternative:
+
+    ┌─────────────────────────────────────────────────┐
+    │ merge { Left = λ(n : Natural) → Natural/even n, │
+    │         Right = λ(b : Bool) → b }               │
+    │   (< Left : Natural >.Left 1)                   │
+    └─────────────────────────────────────────────────┘
+
+Remove the extra handler, or check that its name is spelled correctly.`,
+
+	WrongOperandType: `Explanation: The operand of an operator or built-in has the wrong type.  Each
+operator only accepts operands of particular types; for example ❰+❱ only adds
+❰Natural❱s, and ❰++❱ only concatenates ❰Text❱.
+
+The error message above shows the type which was expected, and the type of the
+operand which was given.`,
+}
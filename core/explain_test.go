@@ -0,0 +1,33 @@
+package core
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+var _ = Describe("Explain", func() {
+	It("Has an explanation for every TypeErrorCode", func() {
+		for _, code := range typeErrorCodes {
+			Expect(explanations).To(HaveKey(code))
+			Expect(explanations[code]).To(HavePrefix("Explanation: "))
+		}
+		Expect(explanations).To(HaveLen(len(typeErrorCodes)))
+	})
+	It("Follows the error message with the explanation", func() {
+		_, err := TypeOf(term.Field{Record: term.RecordLit{}, FieldName: "x"})
+		terr := err.(*TypeError)
+		explained := terr.Explain()
+		Expect(explained).To(HavePrefix(terr.Error() + "\n\nExplanation: "))
+		Expect(explained).To(ContainSubstring("you can only access fields if they are present"))
+	})
+	It("Keeps the location of located errors", func() {
+		span := term.NewSpan("file.dhall", "x", term.Position{Line: 1, Column: 1}, term.Position{Line: 1, Column: 2, Offset: 1})
+		_, err := TypeOf(term.Note{Span: span, Term: term.NewVar("x")})
+		Expect(err.(*TypeError).Explain()).To(HavePrefix("file.dhall:1:1: Unbound variable x\n\nx\n^\n\nExplanation: "))
+	})
+	It("Uses the error message alone for an unknown code", func() {
+		terr := &TypeError{Code: "NoSuchCode", message: typeMessage{format: "oops"}}
+		Expect(terr.Explain()).To(Equal("oops"))
+	})
+})
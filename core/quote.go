@@ -8,13 +8,18 @@ import (
 
 // Quote takes the Value v and turns it back into a Term.
 func Quote(v Value) term.Term {
-	return quoteWith(quoteContext{}, false, v)
+	return quoteWith(nil, quoteContext{}, false, v)
 }
 
 // QuoteAlphaNormal takes the Value v and turns it back into a Term,
 // in alpha-normal form: ie all labels are changed to `_`.
 func QuoteAlphaNormal(v Value) term.Term {
-	return quoteWith(quoteContext{}, true, v)
+	return quoteWith(nil, quoteContext{}, true, v)
+}
+
+// quote is Quote, subject to ev's depth limit.
+func (ev *evaluator) quote(v Value) term.Term {
+	return quoteWith(ev, quoteContext{}, false, v)
 }
 
 // a quoteContext records how many binders of each variable name we have passed
@@ -29,7 +34,9 @@ func (q quoteContext) extend(name string) quoteContext {
 	return newCtx
 }
 
-func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
+func quoteWith(ev *evaluator, ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
+	ev.enter()
+	defer ev.leave()
 	switch v := v.(type) {
 	case Universe:
 		return term.Universe(v)
@@ -44,15 +51,15 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		if v.n == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.n)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.n)}
 		if v.typ == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		if v.succ == nil {
 			return result
 		}
-		return term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.succ)}
+		return term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.succ)}
 	case naturalIsZero:
 		return term.NaturalIsZero
 	case naturalOdd:
@@ -61,7 +68,7 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		return term.NaturalShow
 	case naturalSubtract:
 		if v.a != nil {
-			return term.App{term.NaturalSubtract, quoteWith(ctx, shouldAlphaNormalize, v.a)}
+			return term.App{term.NaturalSubtract, quoteWith(ev, ctx, shouldAlphaNormalize, v.a)}
 		}
 		return term.NaturalSubtract
 	case integerClamp:
@@ -76,6 +83,14 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		return term.IntegerToDouble
 	case doubleShow:
 		return term.DoubleShow
+	case dateShow:
+		return term.DateShow
+	case timeShow:
+		return term.TimeShow
+	case timeZoneShow:
+		return term.TimeZoneShow
+	case bytesShow:
+		return term.BytesShow
 	case optional:
 		return term.Optional
 	case none:
@@ -87,17 +102,17 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		if v.needle == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.needle)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.needle)}
 		if v.replacement == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.replacement)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.replacement)}
 		return result
 	case list:
 		return term.List
 	case listBuild:
 		if v.typ != nil {
-			return term.App{term.ListBuild, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListBuild, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListBuild
 	case listFold:
@@ -105,42 +120,42 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		if v.typ1 == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.typ1)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ1)}
 		if v.list == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.list)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.list)}
 		if v.typ2 == nil {
 			return result
 		}
-		result = term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.typ2)}
+		result = term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ2)}
 		if v.cons == nil {
 			return result
 		}
-		return term.App{result, quoteWith(ctx, shouldAlphaNormalize, v.cons)}
+		return term.App{result, quoteWith(ev, ctx, shouldAlphaNormalize, v.cons)}
 	case listHead:
 		if v.typ != nil {
-			return term.App{term.ListHead, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListHead, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListHead
 	case listIndexed:
 		if v.typ != nil {
-			return term.App{term.ListIndexed, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListIndexed, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListIndexed
 	case listLength:
 		if v.typ != nil {
-			return term.App{term.ListLength, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListLength, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListLength
 	case listLast:
 		if v.typ != nil {
-			return term.App{term.ListLast, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListLast, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListLast
 	case listReverse:
 		if v.typ != nil {
-			return term.App{term.ListReverse, quoteWith(ctx, shouldAlphaNormalize, v.typ)}
+			return term.App{term.ListReverse, quoteWith(ev, ctx, shouldAlphaNormalize, v.typ)}
 		}
 		return term.ListReverse
 	case freeVar:
@@ -160,8 +175,8 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		bodyVal := v.Call(quoteVar{Name: label, Index: ctx[label]})
 		return term.Lambda{
 			Label: label,
-			Type:  quoteWith(ctx, shouldAlphaNormalize, v.Domain),
-			Body:  quoteWith(ctx.extend(label), shouldAlphaNormalize, bodyVal),
+			Type:  quoteWith(ev, ctx, shouldAlphaNormalize, v.Domain),
+			Body:  quoteWith(ev, ctx.extend(label), shouldAlphaNormalize, bodyVal),
 		}
 	case Pi:
 		label := v.Label
@@ -171,19 +186,19 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		bodyVal := v.Codomain(quoteVar{Name: label, Index: ctx[label]})
 		return term.Pi{
 			Label: label,
-			Type:  quoteWith(ctx, shouldAlphaNormalize, v.Domain),
-			Body:  quoteWith(ctx.extend(label), shouldAlphaNormalize, bodyVal),
+			Type:  quoteWith(ev, ctx, shouldAlphaNormalize, v.Domain),
+			Body:  quoteWith(ev, ctx.extend(label), shouldAlphaNormalize, bodyVal),
 		}
 	case app:
 		return term.App{
-			Fn:  quoteWith(ctx, shouldAlphaNormalize, v.Fn),
-			Arg: quoteWith(ctx, shouldAlphaNormalize, v.Arg),
+			Fn:  quoteWith(ev, ctx, shouldAlphaNormalize, v.Fn),
+			Arg: quoteWith(ev, ctx, shouldAlphaNormalize, v.Arg),
 		}
 	case oper:
 		return term.Op{
 			OpCode: v.OpCode,
-			L:      quoteWith(ctx, shouldAlphaNormalize, v.L),
-			R:      quoteWith(ctx, shouldAlphaNormalize, v.R),
+			L:      quoteWith(ev, ctx, shouldAlphaNormalize, v.L),
+			R:      quoteWith(ev, ctx, shouldAlphaNormalize, v.R),
 		}
 	case NaturalLit:
 		return term.NaturalLit(v)
@@ -191,16 +206,28 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		return term.DoubleLit(v)
 	case IntegerLit:
 		return term.IntegerLit(v)
+	case BigNaturalLit:
+		return term.BigNaturalLit{Int: v.Int}
+	case BigIntegerLit:
+		return term.BigIntegerLit{Int: v.Int}
+	case DateLit:
+		return term.DateLit(v)
+	case TimeLit:
+		return term.TimeLit(v)
+	case TimeZoneLit:
+		return term.TimeZoneLit(v)
+	case BytesLit:
+		return term.BytesLit(v)
 	case BoolLit:
 		return term.BoolLit(v)
 	case ListOf:
-		return term.Apply(term.List, quoteWith(ctx, shouldAlphaNormalize, v.Type))
+		return term.Apply(term.List, quoteWith(ev, ctx, shouldAlphaNormalize, v.Type))
 	case EmptyList:
-		return term.EmptyList{Type: quoteWith(ctx, shouldAlphaNormalize, v.Type)}
+		return term.EmptyList{Type: quoteWith(ev, ctx, shouldAlphaNormalize, v.Type)}
 	case NonEmptyList:
 		l := term.NonEmptyList{}
 		for _, e := range v {
-			l = append(l, quoteWith(ctx, shouldAlphaNormalize, e))
+			l = append(l, quoteWith(ev, ctx, shouldAlphaNormalize, e))
 		}
 		return l
 	case PlainTextLit:
@@ -210,7 +237,7 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		for _, chunk := range v.Chunks {
 			newChunks = append(newChunks, term.Chunk{
 				Prefix: chunk.Prefix,
-				Expr:   quoteWith(ctx, shouldAlphaNormalize, chunk.Expr),
+				Expr:   quoteWith(ev, ctx, shouldAlphaNormalize, chunk.Expr),
 			})
 		}
 		return term.TextLit{
@@ -219,49 +246,49 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 		}
 	case ifVal:
 		return term.If{
-			Cond: quoteWith(ctx, shouldAlphaNormalize, v.Cond),
-			T:    quoteWith(ctx, shouldAlphaNormalize, v.T),
-			F:    quoteWith(ctx, shouldAlphaNormalize, v.F),
+			Cond: quoteWith(ev, ctx, shouldAlphaNormalize, v.Cond),
+			T:    quoteWith(ev, ctx, shouldAlphaNormalize, v.T),
+			F:    quoteWith(ev, ctx, shouldAlphaNormalize, v.F),
 		}
 	case OptionalOf:
-		return term.Apply(term.Optional, quoteWith(ctx, shouldAlphaNormalize, v.Type))
+		return term.Apply(term.Optional, quoteWith(ev, ctx, shouldAlphaNormalize, v.Type))
 	case Some:
-		return term.Some{Val: quoteWith(ctx, shouldAlphaNormalize, v.Val)}
+		return term.Some{Val: quoteWith(ev, ctx, shouldAlphaNormalize, v.Val)}
 	case NoneOf:
-		return term.Apply(term.None, quoteWith(ctx, shouldAlphaNormalize, v.Type))
+		return term.Apply(term.None, quoteWith(ev, ctx, shouldAlphaNormalize, v.Type))
 	case RecordType:
 		rt := term.RecordType{}
 		for k, v := range v {
-			rt[k] = quoteWith(ctx, shouldAlphaNormalize, v)
+			rt[k] = quoteWith(ev, ctx, shouldAlphaNormalize, v)
 		}
 		return rt
 	case RecordLit:
 		rt := term.RecordLit{}
 		for k, v := range v {
-			rt[k] = quoteWith(ctx, shouldAlphaNormalize, v)
+			rt[k] = quoteWith(ev, ctx, shouldAlphaNormalize, v)
 		}
 		return rt
 	case toMap:
-		result := term.ToMap{Record: quoteWith(ctx, shouldAlphaNormalize, v.Record)}
+		result := term.ToMap{Record: quoteWith(ev, ctx, shouldAlphaNormalize, v.Record)}
 		if v.Type != nil {
-			result.Type = quoteWith(ctx, shouldAlphaNormalize, v.Type)
+			result.Type = quoteWith(ev, ctx, shouldAlphaNormalize, v.Type)
 		}
 		return result
 	case field:
 		return term.Field{
-			Record:    quoteWith(ctx, shouldAlphaNormalize, v.Record),
+			Record:    quoteWith(ev, ctx, shouldAlphaNormalize, v.Record),
 			FieldName: v.FieldName,
 		}
 	case project:
 		return term.Project{
-			Record:     quoteWith(ctx, shouldAlphaNormalize, v.Record),
+			Record:     quoteWith(ev, ctx, shouldAlphaNormalize, v.Record),
 			FieldNames: v.FieldNames,
 		}
 	case with:
 		return term.With{
-			Record: quoteWith(ctx, shouldAlphaNormalize, v.Record),
+			Record: quoteWith(ev, ctx, shouldAlphaNormalize, v.Record),
 			Path:   v.Path,
-			Value:  quoteWith(ctx, shouldAlphaNormalize, v.Value),
+			Value:  quoteWith(ev, ctx, shouldAlphaNormalize, v.Value),
 		}
 	case UnionType:
 		result := term.UnionType{}
@@ -270,37 +297,39 @@ func quoteWith(ctx quoteContext, shouldAlphaNormalize bool, v Value) term.Term {
 				result[k] = nil
 				continue
 			}
-			result[k] = quoteWith(ctx, shouldAlphaNormalize, v)
+			result[k] = quoteWith(ev, ctx, shouldAlphaNormalize, v)
 		}
 		return result
 	case unionConstructor:
 		return term.Field{
-			Record:    quoteWith(ctx, shouldAlphaNormalize, v.Type),
+			Record:    quoteWith(ev, ctx, shouldAlphaNormalize, v.Type),
 			FieldName: v.Alternative,
 		}
 	case unionVal:
 		var result term.Term = term.Field{
-			Record:    quoteWith(ctx, shouldAlphaNormalize, v.Type),
+			Record:    quoteWith(ev, ctx, shouldAlphaNormalize, v.Type),
 			FieldName: v.Alternative,
 		}
 		if v.Val != nil {
 			result = term.App{
 				Fn:  result,
-				Arg: quoteWith(ctx, shouldAlphaNormalize, v.Val),
+				Arg: quoteWith(ev, ctx, shouldAlphaNormalize, v.Val),
 			}
 		}
 		return result
 	case merge:
 		result := term.Merge{
-			Handler: quoteWith(ctx, shouldAlphaNormalize, v.Handler),
-			Union:   quoteWith(ctx, shouldAlphaNormalize, v.Union),
+			Handler: quoteWith(ev, ctx, shouldAlphaNormalize, v.Handler),
+			Union:   quoteWith(ev, ctx, shouldAlphaNormalize, v.Union),
 		}
 		if v.Annotation != nil {
-			result.Annotation = quoteWith(ctx, shouldAlphaNormalize, v.Annotation)
+			result.Annotation = quoteWith(ev, ctx, shouldAlphaNormalize, v.Annotation)
 		}
 		return result
 	case assert:
-		return term.Assert{Annotation: quoteWith(ctx, shouldAlphaNormalize, v.Annotation)}
+		return term.Assert{Annotation: quoteWith(ev, ctx, shouldAlphaNormalize, v.Annotation)}
+	case showConstructor:
+		return term.ShowConstructor{Expr: quoteWith(ev, ctx, shouldAlphaNormalize, v.Expr)}
 	}
 	panic(fmt.Sprintf("unknown Value type %#v", v))
 }
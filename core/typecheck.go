@@ -6,10 +6,10 @@ import (
 	"github.com/wallyqs/dhall.go/term"
 )
 
-type context map[string][]Value
+type typeContext map[string][]Value
 
-func (ctx context) extend(name string, t Value) context {
-	newctx := context{}
+func (ctx typeContext) extend(name string, t Value) typeContext {
+	newctx := typeContext{}
 	for k, v := range ctx {
 		newctx[k] = v
 	}
@@ -17,16 +17,16 @@ func (ctx context) extend(name string, t Value) context {
 	return newctx
 }
 
-func (ctx context) freshLocal(name string) term.LocalVar {
+func (ctx typeContext) freshLocal(name string) term.LocalVar {
 	return term.LocalVar{Name: name, Index: len(ctx[name])}
 }
 
-func assertTypeIs(ctx context, expr term.Term, expectedType Value, msg typeMessage) error {
-	actualType, err := typeWith(ctx, expr)
+func (ev *evaluator) assertTypeIs(ctx typeContext, expr term.Term, expectedType Value, msg typeMessage) error {
+	actualType, err := ev.typeWith(ctx, expr)
 	if err != nil {
 		return err
 	}
-	if !AlphaEquivalent(expectedType, actualType) {
+	if !ev.alphaEquivalent(expectedType, actualType) {
 		return mkTypeError(msg)
 	}
 	return nil
@@ -57,14 +57,26 @@ func functionCheck(input Universe, output Universe) Universe {
 // TypeOf typechecks a Term, returning the type in normal form.  If
 // typechecking fails, an error is returned.
 func TypeOf(t term.Term) (Value, error) {
-	v, err := typeWith(context{}, t)
+	var ev *evaluator
+	v, err := ev.typeWith(typeContext{}, t)
 	if err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
-func typeWith(ctx context, t term.Term) (Value, error) {
+func (ev *evaluator) typeWith(ctx typeContext, t term.Term) (Value, error) {
+	ev.enter()
+	defer ev.leave()
+	typ, err := ev.typeNode(ctx, t)
+	if terr, ok := err.(*TypeError); ok {
+		// the innermost call locates the error
+		terr.locate(ctx, t)
+	}
+	return typ, err
+}
+
+func (ev *evaluator) typeNode(ctx typeContext, t term.Term) (Value, error) {
 	switch t := t.(type) {
 	case term.Universe:
 		switch t {
@@ -79,7 +91,8 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 	case term.Builtin:
 		switch t {
-		case term.Bool, term.Double, term.Integer, term.Natural, term.Text:
+		case term.Bool, term.Double, term.Integer, term.Natural, term.Text,
+			term.Date, term.Time, term.TimeZone, term.Bytes:
 			return Type, nil
 		case term.DoubleShow:
 			return NewFnType("_", Double, Text), nil
@@ -164,6 +177,14 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			return NewFnType("needle", Text,
 				NewFnType("replacement", Text,
 					NewFnType("haystack", Text, Text))), nil
+		case term.DateShow:
+			return NewFnType("_", Date, Text), nil
+		case term.TimeShow:
+			return NewFnType("_", Time, Text), nil
+		case term.TimeZoneShow:
+			return NewFnType("_", TimeZone, Text), nil
+		case term.BytesShow:
+			return NewFnType("_", Bytes, Text), nil
 		default:
 			return nil, mkTypeError(unhandledTypeCase)
 		}
@@ -178,11 +199,11 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 		return nil, fmt.Errorf("Unknown variable %s", t.Name)
 	case term.App:
-		fnType, err := typeWith(ctx, t.Fn)
+		fnType, err := ev.typeWith(ctx, t.Fn)
 		if err != nil {
 			return nil, err
 		}
-		argType, err := typeWith(ctx, t.Arg)
+		argType, err := ev.typeWith(ctx, t.Arg)
 		if err != nil {
 			return nil, err
 		}
@@ -192,38 +213,38 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 		expectedType := piType.Domain
 		actualType := argType
-		if !AlphaEquivalent(expectedType, actualType) {
-			return nil, mkTypeError(typeMismatch(Quote(expectedType), Quote(actualType)))
+		if !ev.alphaEquivalent(expectedType, actualType) {
+			return nil, mkTypeError(typeMismatch(ev.quote(expectedType), ev.quote(actualType)))
 		}
-		bodyTypeVal := piType.Codomain(Eval(t.Arg))
+		bodyTypeVal := piType.Codomain(ev.evalTerm(t.Arg))
 		return bodyTypeVal, nil
 	case term.Lambda:
-		_, err := typeWith(ctx, t.Type)
+		_, err := ev.typeWith(ctx, t.Type)
 		if err != nil {
 			return nil, err
 		}
-		argType := Eval(t.Type)
+		argType := ev.evalTerm(t.Type)
 		pi := Pi{Label: t.Label, Domain: argType}
 		freshLocal := ctx.freshLocal(t.Label)
-		bt, err := typeWith(
+		bt, err := ev.typeWith(
 			ctx.extend(t.Label, argType),
 			term.Subst(t.Label, freshLocal, t.Body))
 		if err != nil {
 			return nil, err
 		}
 		pi.Codomain = func(x Value) Value {
-			rebound := term.RebindLocal(freshLocal, Quote(bt))
-			return evalWith(rebound, env{
+			rebound := term.RebindLocal(freshLocal, ev.quote(bt))
+			return ev.eval(rebound, env{
 				t.Label: []Value{x},
 			})
 		}
-		_, err = typeWith(ctx, Quote(pi))
+		_, err = ev.typeWith(ctx, ev.quote(pi))
 		if err != nil {
 			return nil, err
 		}
 		return pi, nil
 	case term.Pi:
-		inUniv, err := typeWith(ctx, t.Type)
+		inUniv, err := ev.typeWith(ctx, t.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -232,8 +253,8 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			return nil, mkTypeError(invalidInputType)
 		}
 		freshLocal := ctx.freshLocal(t.Label)
-		outUniv, err := typeWith(
-			ctx.extend(t.Label, Eval(t.Type)),
+		outUniv, err := ev.typeWith(
+			ctx.extend(t.Label, ev.evalTerm(t.Type)),
 			term.Subst(t.Label, freshLocal, t.Body))
 		if err != nil {
 			return nil, err
@@ -243,7 +264,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			return nil, mkTypeError(invalidOutputType)
 		}
 		return functionCheck(i, o), nil
-	case term.NaturalLit:
+	case term.NaturalLit, term.BigNaturalLit:
 		return Natural, nil
 	case term.Let:
 		let := t
@@ -251,41 +272,41 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			binding := let.Bindings[0]
 			let.Bindings = let.Bindings[1:]
 
-			bindingType, err := typeWith(ctx, binding.Value)
+			bindingType, err := ev.typeWith(ctx, binding.Value)
 			if err != nil {
 				return nil, err
 			}
 
 			if binding.Annotation != nil {
-				_, err := typeWith(ctx, binding.Annotation)
+				_, err := ev.typeWith(ctx, binding.Annotation)
 				if err != nil {
 					return nil, err
 				}
-				if !AlphaEquivalent(bindingType, Eval(binding.Annotation)) {
-					return nil, mkTypeError(annotMismatch(binding.Annotation, Quote(bindingType)))
+				if !ev.alphaEquivalent(bindingType, ev.evalTerm(binding.Annotation)) {
+					return nil, mkTypeError(annotMismatch(binding.Annotation, ev.quote(bindingType)))
 				}
 			}
 
-			value := Quote(Eval(binding.Value))
+			value := ev.quote(ev.evalTerm(binding.Value))
 			let = term.Subst(binding.Variable, value, let).(term.Let)
 			ctx = ctx.extend(binding.Variable, bindingType)
 		}
-		return typeWith(ctx, let.Body)
+		return ev.typeWith(ctx, let.Body)
 	case term.Annot:
-		if t.Annotation != term.Sort {
+		if term.StripNotes(t.Annotation) != term.Sort {
 			// Γ ⊢ T₀ : i
-			if _, err := typeWith(ctx, t.Annotation); err != nil {
+			if _, err := ev.typeWith(ctx, t.Annotation); err != nil {
 				return nil, err
 			}
 		}
 		// Γ ⊢ t : T₁
-		actualType, err := typeWith(ctx, t.Expr)
+		actualType, err := ev.typeWith(ctx, t.Expr)
 		if err != nil {
 			return nil, err
 		}
 		// T₀ ≡ T₁
-		if !AlphaEquivalent(Eval(t.Annotation), actualType) {
-			return nil, mkTypeError(annotMismatch(t.Annotation, Quote(actualType)))
+		if !ev.alphaEquivalent(ev.evalTerm(t.Annotation), actualType) {
+			return nil, mkTypeError(annotMismatch(t.Annotation, ev.quote(actualType)))
 		}
 		// ─────────────────
 		// Γ ⊢ (t : T₀) : T₀
@@ -294,7 +315,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		return Double, nil
 	case term.TextLit:
 		for _, chunk := range t.Chunks {
-			err := assertTypeIs(ctx, chunk.Expr, Text,
+			err := ev.assertTypeIs(ctx, chunk.Expr, Text,
 				cantInterpolate)
 			if err != nil {
 				return nil, err
@@ -304,72 +325,80 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 	case term.BoolLit:
 		return Bool, nil
 	case term.If:
-		condType, err := typeWith(ctx, t.Cond)
+		condType, err := ev.typeWith(ctx, t.Cond)
 		if err != nil {
 			return nil, err
 		}
 		if condType != Bool {
 			return nil, mkTypeError(invalidPredicate)
 		}
-		L, err := typeWith(ctx, t.T)
+		L, err := ev.typeWith(ctx, t.T)
 		if err != nil {
 			return nil, err
 		}
 		// no need to check for err here
-		if t, _ := typeWith(ctx, Quote(L)); t != Type {
+		if t, _ := ev.typeWith(ctx, ev.quote(L)); t != Type {
 			return nil, mkTypeError(ifBranchMustBeTerm)
 		}
-		R, err := typeWith(ctx, t.F)
+		R, err := ev.typeWith(ctx, t.F)
 		if err != nil {
 			return nil, err
 		}
-		if t, _ := typeWith(ctx, Quote(R)); t != Type {
+		if t, _ := ev.typeWith(ctx, ev.quote(R)); t != Type {
 			return nil, mkTypeError(ifBranchMustBeTerm)
 		}
-		if !AlphaEquivalent(L, R) {
+		if !ev.alphaEquivalent(L, R) {
 			return nil, mkTypeError(ifBranchMismatch)
 		}
 		return L, nil
-	case term.IntegerLit:
+	case term.IntegerLit, term.BigIntegerLit:
 		return Integer, nil
+	case term.DateLit:
+		return Date, nil
+	case term.TimeLit:
+		return Time, nil
+	case term.TimeZoneLit:
+		return TimeZone, nil
+	case term.BytesLit:
+		return Bytes, nil
 	case term.Op:
 		switch t.OpCode {
 		case term.OrOp, term.AndOp, term.EqOp, term.NeOp:
-			err := assertTypeIs(ctx, t.L, Bool, cantBoolOp(t.OpCode))
+			err := ev.assertTypeIs(ctx, t.L, Bool, cantBoolOp(t.OpCode))
 			if err != nil {
 				return nil, err
 			}
-			err = assertTypeIs(ctx, t.R, Bool, cantBoolOp(t.OpCode))
+			err = ev.assertTypeIs(ctx, t.R, Bool, cantBoolOp(t.OpCode))
 			if err != nil {
 				return nil, err
 			}
 			return Bool, nil
 		case term.PlusOp, term.TimesOp:
-			err := assertTypeIs(ctx, t.L, Natural, cantNaturalOp(t.OpCode))
+			err := ev.assertTypeIs(ctx, t.L, Natural, cantNaturalOp(t.OpCode))
 			if err != nil {
 				return nil, err
 			}
-			err = assertTypeIs(ctx, t.R, Natural, cantNaturalOp(t.OpCode))
+			err = ev.assertTypeIs(ctx, t.R, Natural, cantNaturalOp(t.OpCode))
 			if err != nil {
 				return nil, err
 			}
 			return Natural, nil
 		case term.TextAppendOp:
-			err := assertTypeIs(ctx, t.L, Text, cantTextAppend)
+			err := ev.assertTypeIs(ctx, t.L, Text, cantTextAppend)
 			if err != nil {
 				return nil, err
 			}
-			err = assertTypeIs(ctx, t.R, Text, cantTextAppend)
+			err = ev.assertTypeIs(ctx, t.R, Text, cantTextAppend)
 			if err != nil {
 				return nil, err
 			}
 			return Text, nil
 		case term.ListAppendOp:
-			lt, err := typeWith(ctx, t.L)
+			lt, err := ev.typeWith(ctx, t.L)
 			if err != nil {
 				return nil, err
 			}
-			rt, err := typeWith(ctx, t.R)
+			rt, err := ev.typeWith(ctx, t.R)
 			if err != nil {
 				return nil, err
 			}
@@ -382,38 +411,38 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			if !ok {
 				return nil, mkTypeError(cantListAppend)
 			}
-			if !AlphaEquivalent(lElemT, rElemT) {
+			if !ev.alphaEquivalent(lElemT, rElemT) {
 				return nil, mkTypeError(listAppendMismatch)
 			}
 			return lt, nil
 		case term.RecordMergeOp:
-			lType, err := typeWith(ctx, t.L)
+			lType, err := ev.typeWith(ctx, t.L)
 			if err != nil {
 				return nil, err
 			}
-			rType, err := typeWith(ctx, t.R)
+			rType, err := ev.typeWith(ctx, t.R)
 			if err != nil {
 				return nil, err
 			}
-			recordType := term.Op{L: Quote(lType), R: Quote(rType), OpCode: term.RecordTypeMergeOp}
-			if _, err = typeWith(ctx, recordType); err != nil {
+			recordType := term.Op{L: ev.quote(lType), R: ev.quote(rType), OpCode: term.RecordTypeMergeOp}
+			if _, err = ev.typeWith(ctx, recordType); err != nil {
 				return nil, err
 			}
-			return Eval(recordType), nil
+			return ev.evalTerm(recordType), nil
 		case term.RecordTypeMergeOp:
-			lKind, err := typeWith(ctx, t.L)
+			lKind, err := ev.typeWith(ctx, t.L)
 			if err != nil {
 				return nil, err
 			}
-			rKind, err := typeWith(ctx, t.R)
+			rKind, err := ev.typeWith(ctx, t.R)
 			if err != nil {
 				return nil, err
 			}
-			lt, ok := Eval(t.L).(RecordType)
+			lt, ok := ev.evalTerm(t.L).(RecordType)
 			if !ok {
 				return nil, mkTypeError(combineTypesRequiresRecordType)
 			}
-			rt, ok := Eval(t.R).(RecordType)
+			rt, ok := ev.evalTerm(t.R).(RecordType)
 			if !ok {
 				return nil, mkTypeError(combineTypesRequiresRecordType)
 			}
@@ -426,11 +455,11 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			}
 			return rKind, nil
 		case term.RightBiasedRecordMergeOp:
-			lType, err := typeWith(ctx, t.L)
+			lType, err := ev.typeWith(ctx, t.L)
 			if err != nil {
 				return nil, err
 			}
-			rType, err := typeWith(ctx, t.R)
+			rType, err := ev.typeWith(ctx, t.R)
 			if err != nil {
 				return nil, err
 			}
@@ -451,30 +480,30 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			}
 			return result, nil
 		case term.ImportAltOp:
-			return typeWith(ctx, t.L)
+			return ev.typeWith(ctx, t.L)
 		case term.EquivOp:
-			lType, err := typeWith(ctx, t.L)
+			lType, err := ev.typeWith(ctx, t.L)
 			if err != nil {
 				return nil, err
 			}
-			rType, err := typeWith(ctx, t.R)
+			rType, err := ev.typeWith(ctx, t.R)
 			if err != nil {
 				return nil, err
 			}
-			err = assertTypeIs(ctx, Quote(lType), Type, incomparableExpression)
+			err = ev.assertTypeIs(ctx, ev.quote(lType), Type, incomparableExpression)
 			if err != nil {
 				return nil, err
 			}
-			err = assertTypeIs(ctx, Quote(rType), Type, incomparableExpression)
+			err = ev.assertTypeIs(ctx, ev.quote(rType), Type, incomparableExpression)
 			if err != nil {
 				return nil, err
 			}
-			if !AlphaEquivalent(lType, rType) {
+			if !ev.alphaEquivalent(lType, rType) {
 				return nil, mkTypeError(equivalenceTypeMismatch)
 			}
 			return Type, nil
 		case term.CompleteOp:
-			return typeWith(ctx,
+			return ev.typeWith(ctx,
 				term.Annot{
 					Expr: term.Op{OpCode: term.RightBiasedRecordMergeOp,
 						L: term.Field{Record: t.L, FieldName: "default"},
@@ -487,48 +516,48 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			return nil, fmt.Errorf("Internal error: unknown opcode %v", t.OpCode)
 		}
 	case term.EmptyList:
-		_, err := typeWith(ctx, t.Type)
+		_, err := ev.typeWith(ctx, t.Type)
 		if err != nil {
 			return nil, err
 		}
-		listType := Eval(t.Type)
+		listType := ev.evalTerm(t.Type)
 		_, ok := listElementType(listType)
 		if !ok {
 			return nil, mkTypeError(invalidListType)
 		}
 		return listType, nil
 	case term.NonEmptyList:
-		T0, err := typeWith(ctx, t[0])
+		T0, err := ev.typeWith(ctx, t[0])
 		if err != nil {
 			return nil, err
 		}
-		err = assertTypeIs(ctx, Quote(T0), Type, invalidListType)
+		err = ev.assertTypeIs(ctx, ev.quote(T0), Type, invalidListType)
 		if err != nil {
 			return nil, err
 		}
 		for _, e := range t[1:] {
-			T1, err := typeWith(ctx, e)
+			T1, err := ev.typeWith(ctx, e)
 			if err != nil {
 				return nil, err
 			}
-			if !AlphaEquivalent(T0, T1) {
-				return nil, mkTypeError(mismatchedListElements(Quote(T0), Quote(T1)))
+			if !ev.alphaEquivalent(T0, T1) {
+				return nil, mkTypeError(mismatchedListElements(ev.quote(T0), ev.quote(T1)))
 			}
 		}
 		return ListOf{T0}, nil
 	case term.Some:
-		A, err := typeWith(ctx, t.Val)
+		A, err := ev.typeWith(ctx, t.Val)
 		if err != nil {
 			return nil, err
 		}
-		if err = assertTypeIs(ctx, Quote(A), Type, invalidSome); err != nil {
+		if err = ev.assertTypeIs(ctx, ev.quote(A), Type, invalidSome); err != nil {
 			return nil, err
 		}
 		return OptionalOf{A}, nil
 	case term.RecordType:
 		recordUniverse := Type
 		for _, v := range t {
-			fieldUniverse, err := typeWith(ctx, v)
+			fieldUniverse, err := ev.typeWith(ctx, v)
 			if err != nil {
 				return nil, err
 			}
@@ -544,18 +573,18 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 	case term.RecordLit:
 		recordType := RecordType{}
 		for k, v := range t {
-			fieldType, err := typeWith(ctx, v)
+			fieldType, err := ev.typeWith(ctx, v)
 			if err != nil {
 				return nil, err
 			}
 			recordType[k] = fieldType
 		}
-		if _, err := typeWith(ctx, Quote(recordType)); err != nil {
+		if _, err := ev.typeWith(ctx, ev.quote(recordType)); err != nil {
 			return nil, err
 		}
 		return recordType, nil
 	case term.ToMap:
-		recordTypeVal, err := typeWith(ctx, t.Record)
+		recordTypeVal, err := ev.typeWith(ctx, t.Record)
 		if err != nil {
 			return nil, err
 		}
@@ -568,18 +597,18 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			if t.Type == nil {
 				return nil, mkTypeError(missingToMapType)
 			}
-			err = assertTypeIs(ctx, t.Type, Type, invalidToMapRecordKind)
+			err = ev.assertTypeIs(ctx, t.Type, Type, invalidToMapRecordKind)
 			if err != nil {
 				return nil, err
 			}
-			tVal := Eval(t.Type)
+			tVal := ev.evalTerm(t.Type)
 			t, ok := listElementType(tVal)
 			if !ok {
-				return nil, mkTypeError(invalidToMapType(Quote(tVal)))
+				return nil, mkTypeError(invalidToMapType(ev.quote(tVal)))
 			}
 			rt, ok := t.(RecordType)
 			if !ok || len(rt) != 2 || rt["mapKey"] != Text || rt["mapValue"] == nil {
-				return nil, mkTypeError(invalidToMapType(Quote(tVal)))
+				return nil, mkTypeError(invalidToMapType(ev.quote(tVal)))
 			}
 			return tVal, nil
 		}
@@ -589,28 +618,28 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			if elemType == nil {
 				elemType = v
 			} else {
-				if !AlphaEquivalent(elemType, v) {
+				if !ev.alphaEquivalent(elemType, v) {
 					return nil, mkTypeError(heterogenousRecordToMap)
 				}
 			}
 		}
-		if k, _ := typeWith(ctx, Quote(elemType)); k != Type {
+		if k, _ := ev.typeWith(ctx, ev.quote(elemType)); k != Type {
 			return nil, mkTypeError(invalidToMapRecordKind)
 		}
 		inferred := ListOf{RecordType{"mapKey": Text, "mapValue": elemType}}
 		if t.Type == nil {
 			return inferred, nil
 		}
-		if _, err = typeWith(ctx, t.Type); err != nil {
+		if _, err = ev.typeWith(ctx, t.Type); err != nil {
 			return nil, err
 		}
-		annot := Eval(t.Type)
-		if !AlphaEquivalent(inferred, annot) {
-			return nil, mkTypeError(mapTypeMismatch(Quote(inferred), t.Type))
+		annot := ev.evalTerm(t.Type)
+		if !ev.alphaEquivalent(inferred, annot) {
+			return nil, mkTypeError(mapTypeMismatch(ev.quote(inferred), t.Type))
 		}
 		return inferred, nil
 	case term.Field:
-		recordTypeVal, err := typeWith(ctx, t.Record)
+		recordTypeVal, err := ev.typeWith(ctx, t.Record)
 		if err != nil {
 			return nil, err
 		}
@@ -622,7 +651,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			}
 			return fieldType, nil
 		}
-		unionTypeV := Eval(t.Record)
+		unionTypeV := ev.evalTerm(t.Record)
 		unionType, ok := unionTypeV.(UnionType)
 		if !ok {
 			return nil, mkTypeError(cantAccess)
@@ -640,7 +669,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			Codomain: func(Value) Value { return unionType },
 		}, nil
 	case term.Project:
-		recordTypeVal, err := typeWith(ctx, t.Record)
+		recordTypeVal, err := ev.typeWith(ctx, t.Record)
 		if err != nil {
 			return nil, err
 		}
@@ -664,7 +693,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 		return result, nil
 	case term.ProjectType:
-		recordTypeVal, err := typeWith(ctx, t.Record)
+		recordTypeVal, err := ev.typeWith(ctx, t.Record)
 		if err != nil {
 			return nil, err
 		}
@@ -672,11 +701,11 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		if !ok {
 			return nil, mkTypeError(cantProject)
 		}
-		_, err = typeWith(ctx, t.Selector)
+		_, err = ev.typeWith(ctx, t.Selector)
 		if err != nil {
 			return nil, err
 		}
-		selectorVal := Eval(t.Selector)
+		selectorVal := ev.evalTerm(t.Selector)
 		selector, ok := selectorVal.(RecordType)
 		if !ok {
 			return nil, mkTypeError(cantProjectByExpression)
@@ -687,8 +716,8 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			if !ok {
 				return nil, mkTypeError(missingField)
 			}
-			if !AlphaEquivalent(fieldType, typ) {
-				return nil, mkTypeError(projectionTypeMismatch(Quote(typ), Quote(fieldType)))
+			if !ev.alphaEquivalent(fieldType, typ) {
+				return nil, mkTypeError(projectionTypeMismatch(ev.quote(typ), ev.quote(fieldType)))
 			}
 			result[name] = typ
 		}
@@ -700,7 +729,7 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 				// empty alternative
 				continue
 			}
-			k, err := typeWith(ctx, typ)
+			k, err := ev.typeWith(ctx, typ)
 			if err != nil {
 				return nil, err
 			}
@@ -714,11 +743,11 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 		return universe, nil
 	case term.Merge:
-		handlerTypeVal, err := typeWith(ctx, t.Handler)
+		handlerTypeVal, err := ev.typeWith(ctx, t.Handler)
 		if err != nil {
 			return nil, err
 		}
-		unionTypeV, err := typeWith(ctx, t.Union)
+		unionTypeV, err := ev.typeWith(ctx, t.Union)
 		if err != nil {
 			return nil, err
 		}
@@ -742,10 +771,10 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 			if t.Annotation == nil {
 				return nil, mkTypeError(missingMergeType)
 			}
-			if _, err := typeWith(ctx, t.Annotation); err != nil {
+			if _, err := ev.typeWith(ctx, t.Annotation); err != nil {
 				return nil, err
 			}
-			return Eval(t.Annotation), nil
+			return ev.evalTerm(t.Annotation), nil
 		}
 
 		var result Value
@@ -758,8 +787,8 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 				if result == nil {
 					result = fieldType
 				} else {
-					if !AlphaEquivalent(result, fieldType) {
-						return nil, mkTypeError(handlerOutputTypeMismatch(Quote(result), Quote(fieldType)))
+					if !ev.alphaEquivalent(result, fieldType) {
+						return nil, mkTypeError(handlerOutputTypeMismatch(ev.quote(result), ev.quote(fieldType)))
 					}
 				}
 			} else {
@@ -767,52 +796,52 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 				if !ok {
 					return nil, mkTypeError(handlerNotAFunction)
 				}
-				if !AlphaEquivalent(altType, pi.Domain) {
-					return nil, mkTypeError(handlerInputTypeMismatch(Quote(altType), Quote(pi.Domain)))
+				if !ev.alphaEquivalent(altType, pi.Domain) {
+					return nil, mkTypeError(handlerInputTypeMismatch(ev.quote(altType), ev.quote(pi.Domain)))
 				}
 				outputType := pi.Codomain(NaturalLit(1))
 				outputType2 := pi.Codomain(NaturalLit(2))
-				if !AlphaEquivalent(outputType, outputType2) {
+				if !ev.alphaEquivalent(outputType, outputType2) {
 					// hacky way of detecting output type depending on input
 					return nil, mkTypeError(disallowedHandlerType)
 				}
 				if result == nil {
 					result = outputType
 				} else {
-					if !AlphaEquivalent(result, outputType) {
-						return nil, mkTypeError(handlerOutputTypeMismatch(Quote(result), Quote(outputType)))
+					if !ev.alphaEquivalent(result, outputType) {
+						return nil, mkTypeError(handlerOutputTypeMismatch(ev.quote(result), ev.quote(outputType)))
 					}
 				}
 			}
 		}
 		if t.Annotation != nil {
-			if _, err := typeWith(ctx, t.Annotation); err != nil {
+			if _, err := ev.typeWith(ctx, t.Annotation); err != nil {
 				return nil, err
 			}
-			if !AlphaEquivalent(result, Eval(t.Annotation)) {
-				return nil, mkTypeError(annotMismatch(t.Annotation, Quote(result)))
+			if !ev.alphaEquivalent(result, ev.evalTerm(t.Annotation)) {
+				return nil, mkTypeError(annotMismatch(t.Annotation, ev.quote(result)))
 			}
 		}
 		return result, nil
 	case term.Assert:
-		err := assertTypeIs(ctx, t.Annotation, Type, notAnEquivalence)
+		err := ev.assertTypeIs(ctx, t.Annotation, Type, notAnEquivalence)
 		if err != nil {
 			return nil, err
 		}
-		oper, ok := Eval(t.Annotation).(oper)
+		oper, ok := ev.evalTerm(t.Annotation).(oper)
 		if !ok || oper.OpCode != term.EquivOp {
 			return nil, mkTypeError(notAnEquivalence)
 		}
-		if !AlphaEquivalent(oper.L, oper.R) {
-			return nil, mkTypeError(assertionFailed(Quote(oper.L), Quote(oper.R)))
+		if !ev.alphaEquivalent(oper.L, oper.R) {
+			return nil, mkTypeError(assertionFailed(ev.quote(oper.L), ev.quote(oper.R)))
 		}
 		return oper, nil
 	case term.With:
-		recordType, err := typeWith(ctx, t.Record)
+		recordType, err := ev.typeWith(ctx, t.Record)
 		if err != nil {
 			return nil, err
 		}
-		valueType, err := typeWith(ctx, t.Value)
+		valueType, err := ev.typeWith(ctx, t.Value)
 		if err != nil {
 			return nil, err
 		}
@@ -833,154 +862,185 @@ func typeWith(ctx context, t term.Term) (Value, error) {
 		}
 		here[t.Path[len(t.Path)-1]] = valueType
 		return recordType, nil
+	case term.ShowConstructor:
+		exprType, err := ev.typeWith(ctx, t.Expr)
+		if err != nil {
+			return nil, err
+		}
+		switch exprType.(type) {
+		case UnionType, OptionalOf:
+			return Text, nil
+		}
+		return nil, mkTypeError(showConstructorNotOnUnion)
+	case term.Note:
+		typ, err := ev.typeWith(ctx, t.Term)
+		if terr, ok := err.(*TypeError); ok && terr.Span == nil {
+			// the innermost Note locates the error
+			terr.Span = &t.Span
+		}
+		return typ, err
 	}
 	return nil, mkTypeError(unhandledTypeCase)
 }
 
-type typeError struct {
-	ctx     context
-	message typeMessage
-}
-
-func mkTypeError(message typeMessage) typeError {
-	return typeError{message: message}
-}
-
-func (t typeError) Error() string {
-	return t.message.String()
-}
-
-type typeMessage interface {
-	String() string
+func mkTypeError(message typeMessage) *TypeError {
+	return &TypeError{
+		Code:     message.code,
+		Expected: message.expected,
+		Actual:   message.actual,
+		message:  message,
+	}
 }
 
-type staticTypeMessage struct{ text string }
-type oneArgTypeMessage struct {
-	format string
-	expr   term.Term
-}
-type twoArgTypeMessage struct {
-	format string
-	expr0  term.Term
-	expr1  term.Term
+// A typeMessage describes a type error.  format is formatted with
+// args, if there are any.
+type typeMessage struct {
+	code     TypeErrorCode
+	format   string
+	args     []interface{}
+	expected term.Term
+	actual   term.Term
 }
 
-func (m staticTypeMessage) String() string { return m.text }
-func (m oneArgTypeMessage) String() string {
-	return fmt.Sprintf(m.format, m.expr)
-}
-func (m twoArgTypeMessage) String() string {
-	return fmt.Sprintf(m.format, m.expr0, m.expr1)
+func (m typeMessage) String() string {
+	if len(m.args) == 0 {
+		return m.format
+	}
+	return fmt.Sprintf(m.format, m.args...)
 }
 
 func unboundVariable(e term.Term) typeMessage {
-	return oneArgTypeMessage{
+	return typeMessage{
+		code:   UnboundVariable,
 		format: "Unbound variable: %v",
-		expr:   e,
+		args:   []interface{}{e},
+		actual: e,
 	}
 }
 
 func annotMismatch(annotation, actualType term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: AnnotationMismatch,
 		format: "Expression doesn't match annotation\n" +
 			"\n" +
 			"Expression of type %v was annotated %v",
-		expr0: actualType,
-		expr1: annotation,
+		args:     []interface{}{actualType, annotation},
+		expected: annotation,
+		actual:   actualType,
 	}
 }
 
 func wrongOperandType(expectedType, actualType term.Term) typeMessage {
-	return twoArgTypeMessage{
-		format: "Expected %v but got %v",
-		expr0:  expectedType,
-		expr1:  actualType,
+	return typeMessage{
+		code:     WrongOperandType,
+		format:   "Expected %v but got %v",
+		args:     []interface{}{expectedType, actualType},
+		expected: expectedType,
+		actual:   actualType,
 	}
 }
 
 func typeMismatch(expectedType, actualType term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: TypeMismatch,
 		format: "Wrong type of function argument\n" +
 			"\n" +
 			"expected %v but got %v",
-		expr0: expectedType,
-		expr1: actualType,
+		args:     []interface{}{expectedType, actualType},
+		expected: expectedType,
+		actual:   actualType,
 	}
 }
 
 func mismatchedListElements(firstType, nthType term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: MismatchedListElements,
 		format: "List elements should all have the same type\n" +
 			"\n" +
 			"first element had type %v but there was an element of type %v",
-		expr0: firstType,
-		expr1: nthType,
+		args:     []interface{}{firstType, nthType},
+		expected: firstType,
+		actual:   nthType,
 	}
 }
 
 func mapTypeMismatch(inferred, annotated term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: MapTypeMismatch,
 		format: "❰toMap❱ result type doesn't match annotation\n" +
 			"\n" +
 			"map had type %v but was annotated %v",
-		expr0: inferred,
-		expr1: annotated,
+		args:     []interface{}{inferred, annotated},
+		expected: annotated,
+		actual:   inferred,
 	}
 }
 
 func invalidToMapType(expr term.Term) typeMessage {
-	return oneArgTypeMessage{
+	return typeMessage{
+		code: InvalidToMapType,
 		format: "An empty ❰toMap❱ was annotated with an invalid type\n" +
 			"\n" +
 			"%v",
-		expr: expr,
+		args:   []interface{}{expr},
+		actual: expr,
 	}
 }
 
 func handlerOutputTypeMismatch(type1, type2 term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: HandlerOutputTypeMismatch,
 		format: "Handlers should have the same output type\n" +
 			"\n" +
 			"Saw handlers of types %v and %v",
-		expr0: type1,
-		expr1: type2,
+		args:     []interface{}{type1, type2},
+		expected: type2,
+		actual:   type1,
 	}
 }
 
 func handlerInputTypeMismatch(altType, inputType term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: HandlerInputTypeMismatch,
 		format: "Wrong handler input type\n" +
 			"\n" +
 			"Expected input type %v but saw %v",
-		expr0: altType,
-		expr1: inputType,
+		args:     []interface{}{altType, inputType},
+		expected: altType,
+		actual:   inputType,
 	}
 }
 
 func projectionTypeMismatch(firstType, secondType term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: ProjectionTypeMismatch,
 		format: "Projection type mismatch\n" +
 			"\n" +
 			"tried to project a %v but the field had type %v",
-		expr0: firstType,
-		expr1: secondType,
+		args:     []interface{}{firstType, secondType},
+		expected: firstType,
+		actual:   secondType,
 	}
 }
 
 func assertionFailed(leftTerm, rightTerm term.Term) typeMessage {
-	return twoArgTypeMessage{
+	return typeMessage{
+		code: AssertionFailed,
 		format: "Assertion failed\n" +
 			"\n" +
 			"%v is not equivalent to %v",
-		expr0: leftTerm,
-		expr1: rightTerm,
+		args:     []interface{}{leftTerm, rightTerm},
+		expected: rightTerm,
+		actual:   leftTerm,
 	}
 }
 
 func typeCheckVar(boundVar term.Term) typeMessage {
-	return oneArgTypeMessage{
+	return typeMessage{
+		code:   UnboundVariable,
 		format: "Unbound variable %s",
-		expr:   boundVar,
+		args:   []interface{}{boundVar},
+		actual: boundVar,
 	}
 }
 
@@ -998,7 +1058,7 @@ func cantBoolOp(opCode term.OpCode) typeMessage {
 	default:
 		panic(fmt.Sprintf("unknown boolean opcode %d", opCode))
 	}
-	return staticTypeMessage{fmt.Sprintf("❰%s❱ only works on ❰Bool❱s", opStr)}
+	return typeMessage{code: CantBoolOp, format: fmt.Sprintf("❰%s❱ only works on ❰Bool❱s", opStr)}
 }
 
 func cantNaturalOp(opCode term.OpCode) typeMessage {
@@ -1011,61 +1071,63 @@ func cantNaturalOp(opCode term.OpCode) typeMessage {
 	default:
 		panic(fmt.Sprintf("unknown natural opcode %d", opCode))
 	}
-	return staticTypeMessage{fmt.Sprintf("❰%s❱ only works on ❰Natural❱s", opStr)}
+	return typeMessage{code: CantNaturalOp, format: fmt.Sprintf("❰%s❱ only works on ❰Natural❱s", opStr)}
 }
 
 func duplicateProjectedField(name string) typeMessage {
-	return staticTypeMessage{fmt.Sprintf("Duplicate field ❰%s❱ in projection expression", name)}
+	return typeMessage{code: DuplicateProjectedField, format: fmt.Sprintf("Duplicate field ❰%s❱ in projection expression", name)}
 }
 
 var (
-	ifBranchMismatch   = staticTypeMessage{"❰if❱ branches must have matching types"}
-	ifBranchMustBeTerm = staticTypeMessage{"❰if❱ branch is not a term"}
-	invalidFieldType   = staticTypeMessage{"Invalid field type"}
-	invalidListType    = staticTypeMessage{"Invalid type for ❰List❱"}
-	invalidInputType   = staticTypeMessage{"Invalid function input"}
-	invalidOutputType  = staticTypeMessage{"Invalid function output"}
-	invalidPredicate   = staticTypeMessage{"Invalid predicate for ❰if❱"}
-	invalidSome        = staticTypeMessage{"❰Some❱ argument has the wrong type"}
+	ifBranchMismatch   = typeMessage{code: IfBranchMismatch, format: "❰if❱ branches must have matching types"}
+	ifBranchMustBeTerm = typeMessage{code: IfBranchMustBeTerm, format: "❰if❱ branch is not a term"}
+	invalidFieldType   = typeMessage{code: InvalidFieldType, format: "Invalid field type"}
+	invalidListType    = typeMessage{code: InvalidListType, format: "Invalid type for ❰List❱"}
+	invalidInputType   = typeMessage{code: InvalidInputType, format: "Invalid function input"}
+	invalidOutputType  = typeMessage{code: InvalidOutputType, format: "Invalid function output"}
+	invalidPredicate   = typeMessage{code: InvalidPredicate, format: "Invalid predicate for ❰if❱"}
+	invalidSome        = typeMessage{code: InvalidSome, format: "❰Some❱ argument has the wrong type"}
+
+	invalidAlternativeType        = typeMessage{code: InvalidAlternativeType, format: "Invalid alternative type"}
+	alternativeAnnotationMismatch = typeMessage{code: AlternativeAnnotationMismatch, format: "Alternative annotation mismatch"}
 
-	invalidAlternativeType        = staticTypeMessage{"Invalid alternative type"}
-	alternativeAnnotationMismatch = staticTypeMessage{"Alternative annotation mismatch"}
+	notAFunction = typeMessage{code: NotAFunction, format: "Not a function"}
+	untyped      = typeMessage{code: Untyped, format: "❰Sort❱ has no type, kind, or sort"}
 
-	notAFunction = staticTypeMessage{"Not a function"}
-	untyped      = staticTypeMessage{"❰Sort❱ has no type, kind, or sort"}
+	incomparableExpression  = typeMessage{code: IncomparableExpression, format: "Incomparable expression"}
+	equivalenceTypeMismatch = typeMessage{code: EquivalenceTypeMismatch, format: "The two sides of the equivalence have different types"}
 
-	incomparableExpression  = staticTypeMessage{"Incomparable expression"}
-	equivalenceTypeMismatch = staticTypeMessage{"The two sides of the equivalence have different types"}
+	invalidToMapRecordKind  = typeMessage{code: InvalidToMapRecordKind, format: "❰toMap❱ expects a record of kind ❰Type❱"}
+	heterogenousRecordToMap = typeMessage{code: HeterogenousRecordToMap, format: "❰toMap❱ expects a homogenous record"}
+	missingToMapType        = typeMessage{code: MissingToMapType, format: "An empty ❰toMap❱ requires a type annotation"}
 
-	invalidToMapRecordKind  = staticTypeMessage{"❰toMap❱ expects a record of kind ❰Type❱"}
-	heterogenousRecordToMap = staticTypeMessage{"❰toMap❱ expects a homogenous record"}
-	missingToMapType        = staticTypeMessage{"An empty ❰toMap❱ requires a type annotation"}
+	mustMergeARecord      = typeMessage{code: MustMergeARecord, format: "❰merge❱ expects a record of handlers"}
+	mustMergeUnion        = typeMessage{code: MustMergeUnion, format: "❰merge❱ expects a union or Optional"}
+	missingMergeType      = typeMessage{code: MissingMergeType, format: "An empty ❰merge❱ requires a type annotation"}
+	unusedHandler         = typeMessage{code: UnusedHandler, format: "Unused handler"}
+	missingHandler        = typeMessage{code: MissingHandler, format: "Missing handler"}
+	handlerNotAFunction   = typeMessage{code: HandlerNotAFunction, format: "Handler is not a function"}
+	disallowedHandlerType = typeMessage{code: DisallowedHandlerType, format: "Disallowed handler type"}
 
-	mustMergeARecord      = staticTypeMessage{"❰merge❱ expects a record of handlers"}
-	mustMergeUnion        = staticTypeMessage{"❰merge❱ expects a union or Optional"}
-	missingMergeType      = staticTypeMessage{"An empty ❰merge❱ requires a type annotation"}
-	unusedHandler         = staticTypeMessage{"Unused handler"}
-	missingHandler        = staticTypeMessage{"Missing handler"}
-	handlerNotAFunction   = staticTypeMessage{"Handler is not a function"}
-	disallowedHandlerType = staticTypeMessage{"Disallowed handler type"}
+	cantInterpolate = typeMessage{code: CantInterpolate, format: "You can only interpolate ❰Text❱"}
 
-	cantInterpolate = staticTypeMessage{"You can only interpolate ❰Text❱"}
+	cantTextAppend     = typeMessage{code: CantTextAppend, format: "❰++❱ only works on ❰Text❱"}
+	cantListAppend     = typeMessage{code: CantListAppend, format: "❰#❱ only works on ❰List❱s"}
+	listAppendMismatch = typeMessage{code: ListAppendMismatch, format: "You can only append ❰List❱s with matching element types"}
 
-	cantTextAppend     = staticTypeMessage{"❰++❱ only works on ❰Text❱"}
-	cantListAppend     = staticTypeMessage{"❰#❱ only works on ❰List❱s"}
-	listAppendMismatch = staticTypeMessage{"You can only append ❰List❱s with matching element types"}
+	mustCombineARecord = typeMessage{code: MustCombineARecord, format: "You can only combine records"}
 
-	mustCombineARecord = staticTypeMessage{"You can only combine records"}
+	combineTypesRequiresRecordType = typeMessage{code: CombineTypesRequiresRecordType, format: "❰⩓❱ requires arguments that are record types"}
 
-	combineTypesRequiresRecordType = staticTypeMessage{"❰⩓❱ requires arguments that are record types"}
+	cantAccess              = typeMessage{code: CantAccess, format: "Not a record or a union"}
+	cantProject             = typeMessage{code: CantProject, format: "Not a record"}
+	cantProjectByExpression = typeMessage{code: CantProjectByExpression, format: "Selector is not a record type"}
+	missingField            = typeMessage{code: MissingField, format: "Missing record field"}
+	missingConstructor      = typeMessage{code: MissingConstructor, format: "Missing constructor"}
 
-	cantAccess              = staticTypeMessage{"Not a record or a union"}
-	cantProject             = staticTypeMessage{"Not a record"}
-	cantProjectByExpression = staticTypeMessage{"Selector is not a record type"}
-	missingField            = staticTypeMessage{"Missing record field"}
-	missingConstructor      = staticTypeMessage{"Missing constructor"}
+	unhandledTypeCase = typeMessage{code: UnhandledTypeCase, format: "Internal error: unhandled case in TypeOf()"}
 
-	unhandledTypeCase = staticTypeMessage{"Internal error: unhandled case in TypeOf()"}
+	notAnEquivalence = typeMessage{code: NotAnEquivalence, format: "Not an equivalence"}
 
-	notAnEquivalence = staticTypeMessage{"Not an equivalence"}
+	showConstructorNotOnUnion = typeMessage{code: ShowConstructorNotOnUnion, format: "❰showConstructor❱ expects a union or Optional"}
 )
@@ -1,6 +1,11 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -72,6 +77,11 @@ var _ = Describe("TypeOf", func() {
 	DescribeTable("Others",
 		typecheckTest,
 		Entry(`3 : Natural`, term.NaturalLit(3), Natural),
+		Entry(`2020-01-01 : Date`, term.DateLit{2020, 1, 1}, Date),
+		Entry(`00:00:00 : Time`, term.TimeLit{0, 0, 0, ""}, Time),
+		Entry(`+01:00 : TimeZone`, term.TimeZoneLit(60), TimeZone),
+		Entry(`Time/show : Time → Text`, term.TimeShow, NewFnType("_", Time, Text)),
+		Entry(`0x"00" : Bytes`, term.BytesLit{0}, Bytes),
 		Entry(`[] : List Natural : List Natural`,
 			term.EmptyList{term.Apply(term.List, term.Natural)}, ListOf{Natural}),
 	)
@@ -108,3 +118,93 @@ var _ = Describe("TypeOf", func() {
 			term.Equivalent(term.NaturalLit(2), term.Type), "Incomparable expression"),
 	)
 })
+
+var _ = Describe("TypeOfContext", func() {
+	It("Stops typechecking when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		// assert : Natural/fold 100000000000 Integer Integer/negate +1 ≡ +1
+		slowFold := term.Apply(term.NaturalFold,
+			term.NaturalLit(100000000000), term.Integer, term.IntegerNegate, term.IntegerLit(1))
+		_, err := TypeOfContext(ctx, term.Assert{Annotation: term.Equivalent(slowFold, term.IntegerLit(1))})
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
+
+var _ = Describe("Located type errors", func() {
+	source := "1 + x"
+	span := term.NewSpan("file.dhall", source,
+		term.Position{Line: 1, Column: 5, Offset: 4},
+		term.Position{Line: 1, Column: 6, Offset: 5})
+	It("Reports the innermost Note", func() {
+		outer := term.NewSpan("file.dhall", source,
+			term.Position{Line: 1, Column: 1, Offset: 0},
+			term.Position{Line: 1, Column: 6, Offset: 5})
+		_, err := TypeOf(term.Note{Span: outer, Term: term.NaturalPlus(
+			term.NaturalLit(1),
+			term.Note{Span: span, Term: term.NewVar("x")})})
+		Expect(err).To(MatchError("file.dhall:1:5: Unbound variable x\n\n1 + x\n    ^"))
+	})
+	It("Typechecks through Notes", func() {
+		Expect(TypeOf(term.Note{Span: span, Term: term.NaturalLit(1)})).
+			To(Equal(Natural))
+		Expect(TypeOf(term.Annot{Expr: term.Type, Annotation: term.Note{Span: span, Term: term.Kind}})).
+			To(Equal(Kind))
+	})
+})
+
+var _ = Describe("TypeError", func() {
+	DescribeTable("Codes",
+		func(t term.Term, code TypeErrorCode) {
+			_, err := TypeOf(t)
+			var terr *TypeError
+			Expect(errors.As(err, &terr)).To(BeTrue())
+			Expect(terr.Code).To(Equal(code))
+		},
+		Entry(`x`, term.NewVar("x"), UnboundVariable),
+		Entry(`List 3`,
+			term.Apply(term.List, term.NaturalLit(3)), TypeMismatch),
+		Entry(`{=}.x`,
+			term.Field{Record: term.RecordLit{}, FieldName: "x"}, MissingField),
+		Entry(`Natural Natural`,
+			term.Apply(term.Natural, term.Natural), NotAFunction),
+		Entry(`2 === Type`,
+			term.Equivalent(term.NaturalLit(2), term.Type), IncomparableExpression),
+	)
+	It("Records the expected and actual terms", func() {
+		_, err := TypeOf(term.Apply(term.List, term.NaturalLit(3)))
+		var terr *TypeError
+		Expect(errors.As(err, &terr)).To(BeTrue())
+		Expect(terr.Expected).To(Equal(term.Type))
+		Expect(terr.Actual).To(Equal(term.Natural))
+		Expect(terr.Expr).To(Equal(term.Apply(term.List, term.NaturalLit(3))))
+	})
+	It("Records the typing context of the failing subterm", func() {
+		// λ(x : Natural) → λ(x : Text) → x.y
+		_, err := TypeOf(
+			term.NewLambda("x", term.Natural,
+				term.NewLambda("x", term.Text,
+					term.Field{Record: term.NewVar("x"), FieldName: "y"})))
+		var terr *TypeError
+		Expect(errors.As(err, &terr)).To(BeTrue())
+		Expect(terr.Code).To(Equal(CantAccess))
+		Expect(terr.Expr).To(Equal(term.Field{Record: term.NewVar("x"), FieldName: "y"}))
+		Expect(terr.Context).To(Equal(map[string][]term.Term{
+			"x": {term.Natural, term.Text},
+		}))
+	})
+	It("Strips Notes from the failing subterm", func() {
+		span := term.NewSpan("file.dhall", "x", term.Position{Line: 1, Column: 1}, term.Position{Line: 1, Column: 2, Offset: 1})
+		_, err := TypeOf(term.Note{Span: span, Term: term.NewVar("x")})
+		var terr *TypeError
+		Expect(errors.As(err, &terr)).To(BeTrue())
+		Expect(terr.Expr).To(Equal(term.NewVar("x")))
+		Expect(terr.Span).To(Equal(&span))
+	})
+	It("Is found through wrapped errors", func() {
+		_, err := TypeOf(term.NewVar("x"))
+		var terr *TypeError
+		Expect(errors.As(fmt.Errorf("loading config: %w", err), &terr)).To(BeTrue())
+		Expect(terr.Code).To(Equal(UnboundVariable))
+	})
+})
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// A TypeErrorCode identifies the kind of a TypeError.  Codes are
+// stable, and can be used to look up an explanation of the error.
+type TypeErrorCode string
+
+// The kinds of TypeError.
+const (
+	AlternativeAnnotationMismatch  TypeErrorCode = "AlternativeAnnotationMismatch"
+	AnnotationMismatch             TypeErrorCode = "AnnotationMismatch"
+	AssertionFailed                TypeErrorCode = "AssertionFailed"
+	CantAccess                     TypeErrorCode = "CantAccess"
+	CantBoolOp                     TypeErrorCode = "CantBoolOp"
+	CantInterpolate                TypeErrorCode = "CantInterpolate"
+	CantListAppend                 TypeErrorCode = "CantListAppend"
+	CantNaturalOp                  TypeErrorCode = "CantNaturalOp"
+	CantProject                    TypeErrorCode = "CantProject"
+	CantProjectByExpression        TypeErrorCode = "CantProjectByExpression"
+	CantTextAppend                 TypeErrorCode = "CantTextAppend"
+	CombineTypesRequiresRecordType TypeErrorCode = "CombineTypesRequiresRecordType"
+	DisallowedHandlerType          TypeErrorCode = "DisallowedHandlerType"
+	DuplicateProjectedField        TypeErrorCode = "DuplicateProjectedField"
+	EquivalenceTypeMismatch        TypeErrorCode = "EquivalenceTypeMismatch"
+	HandlerInputTypeMismatch       TypeErrorCode = "HandlerInputTypeMismatch"
+	HandlerNotAFunction            TypeErrorCode = "HandlerNotAFunction"
+	HandlerOutputTypeMismatch      TypeErrorCode = "HandlerOutputTypeMismatch"
+	HeterogenousRecordToMap        TypeErrorCode = "HeterogenousRecordToMap"
+	IfBranchMismatch               TypeErrorCode = "IfBranchMismatch"
+	IfBranchMustBeTerm             TypeErrorCode = "IfBranchMustBeTerm"
+	IncomparableExpression         TypeErrorCode = "IncomparableExpression"
+	InvalidAlternativeType         TypeErrorCode = "InvalidAlternativeType"
+	InvalidFieldType               TypeErrorCode = "InvalidFieldType"
+	InvalidInputType               TypeErrorCode = "InvalidInputType"
+	InvalidListType                TypeErrorCode = "InvalidListType"
+	InvalidOutputType              TypeErrorCode = "InvalidOutputType"
+	InvalidPredicate               TypeErrorCode = "InvalidPredicate"
+	InvalidSome                    TypeErrorCode = "InvalidSome"
+	InvalidToMapRecordKind         TypeErrorCode = "InvalidToMapRecordKind"
+	InvalidToMapType               TypeErrorCode = "InvalidToMapType"
+	ListAppendMismatch             TypeErrorCode = "ListAppendMismatch"
+	MapTypeMismatch                TypeErrorCode = "MapTypeMismatch"
+	MismatchedListElements         TypeErrorCode = "MismatchedListElements"
+	MissingConstructor             TypeErrorCode = "MissingConstructor"
+	MissingField                   TypeErrorCode = "MissingField"
+	MissingHandler                 TypeErrorCode = "MissingHandler"
+	MissingMergeType               TypeErrorCode = "MissingMergeType"
+	MissingToMapType               TypeErrorCode = "MissingToMapType"
+	MustCombineARecord             TypeErrorCode = "MustCombineARecord"
+	MustMergeARecord               TypeErrorCode = "MustMergeARecord"
+	MustMergeUnion                 TypeErrorCode = "MustMergeUnion"
+	NotAFunction                   TypeErrorCode = "NotAFunction"
+	NotAnEquivalence               TypeErrorCode = "NotAnEquivalence"
+	ProjectionTypeMismatch         TypeErrorCode = "ProjectionTypeMismatch"
+	ShowConstructorNotOnUnion      TypeErrorCode = "ShowConstructorNotOnUnion"
+	TypeMismatch                   TypeErrorCode = "TypeMismatch"
+	UnboundVariable                TypeErrorCode = "UnboundVariable"
+	UnhandledTypeCase              TypeErrorCode = "UnhandledTypeCase"
+	Untyped                        TypeErrorCode = "Untyped"
+	UnusedHandler                  TypeErrorCode = "UnusedHandler"
+	WrongOperandType               TypeErrorCode = "WrongOperandType"
+)
+
+// typeErrorCodes lists every TypeErrorCode.  A new code belongs
+// here too, with an explanation in explain.go.
+var typeErrorCodes = []TypeErrorCode{
+	AlternativeAnnotationMismatch,
+	AnnotationMismatch,
+	AssertionFailed,
+	CantAccess,
+	CantBoolOp,
+	CantInterpolate,
+	CantListAppend,
+	CantNaturalOp,
+	CantProject,
+	CantProjectByExpression,
+	CantTextAppend,
+	CombineTypesRequiresRecordType,
+	DisallowedHandlerType,
+	DuplicateProjectedField,
+	EquivalenceTypeMismatch,
+	HandlerInputTypeMismatch,
+	HandlerNotAFunction,
+	HandlerOutputTypeMismatch,
+	HeterogenousRecordToMap,
+	IfBranchMismatch,
+	IfBranchMustBeTerm,
+	IncomparableExpression,
+	InvalidAlternativeType,
+	InvalidFieldType,
+	InvalidInputType,
+	InvalidListType,
+	InvalidOutputType,
+	InvalidPredicate,
+	InvalidSome,
+	InvalidToMapRecordKind,
+	InvalidToMapType,
+	ListAppendMismatch,
+	MapTypeMismatch,
+	MismatchedListElements,
+	MissingConstructor,
+	MissingField,
+	MissingHandler,
+	MissingMergeType,
+	MissingToMapType,
+	MustCombineARecord,
+	MustMergeARecord,
+	MustMergeUnion,
+	NotAFunction,
+	NotAnEquivalence,
+	ProjectionTypeMismatch,
+	ShowConstructorNotOnUnion,
+	TypeMismatch,
+	UnboundVariable,
+	UnhandledTypeCase,
+	Untyped,
+	UnusedHandler,
+	WrongOperandType,
+}
+
+// A TypeError is returned when a Term fails to typecheck.  Use
+// errors.As to retrieve it from the error returned by TypeOf() and
+// friends.
+type TypeError struct {
+	Code TypeErrorCode
+	// Expr is the subterm which failed to typecheck
+	Expr term.Term
+	// Expected and Actual are the expected and offending terms
+	// (usually types), if the error has them
+	Expected term.Term
+	Actual   term.Term
+	// Context maps each variable in scope at Expr to its types, in
+	// normal form.  The innermost binding of each name is last.
+	//
+	// Variables in all of these terms are relative to the scope of
+	// Expr.
+	Context map[string][]term.Term
+	// Span is where Expr was parsed from, if the Term came from the
+	// parser with spans
+	Span *term.Span
+
+	message typeMessage
+}
+
+func (e *TypeError) Error() string {
+	if e.Span == nil {
+		return e.message.String()
+	}
+	msg := fmt.Sprintf("%s: %s", e.Span, e.message)
+	if excerpt := e.Span.Excerpt(); excerpt != "" {
+		msg += "\n\n" + excerpt
+	}
+	return msg
+}
+
+// locate fills in the subterm and context of e, if they are not
+// already set.
+func (e *TypeError) locate(ctx typeContext, t term.Term) {
+	if e.Expr != nil {
+		return
+	}
+	e.Expr = ctx.unbind(term.StripNotes(t))
+	if e.Expected != nil {
+		e.Expected = ctx.unbind(e.Expected)
+	}
+	if e.Actual != nil {
+		e.Actual = ctx.unbind(e.Actual)
+	}
+	e.Context = make(map[string][]term.Term, len(ctx))
+	for name, types := range ctx {
+		for _, typ := range types {
+			e.Context[name] = append(e.Context[name], ctx.unbind(Quote(typ)))
+		}
+	}
+}
+
+// unbind replaces the LocalVars in t which are bound by ctx with the
+// equivalent Vars.
+func (ctx typeContext) unbind(t term.Term) term.Term {
+	for name, types := range ctx {
+		// rebind from the innermost binding outwards, wrapping t in
+		// a binder each time so that outer bindings get higher
+		// indices
+		for i := len(types) - 1; i >= 0; i-- {
+			t = term.Lambda{
+				Label: name,
+				Type:  term.Type,
+				Body:  term.RebindLocal(term.LocalVar{Name: name, Index: i}, t),
+			}
+		}
+		for range types {
+			t = t.(term.Lambda).Body
+		}
+	}
+	return t
+}
@@ -0,0 +1,31 @@
+package dhall_test
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/wallyqs/dhall.go"
+)
+
+//go:embed testdata/fs
+var configFS embed.FS
+
+// AppConfig is the struct we want to unmarshal from the embedded Dhall
+// files
+type AppConfig struct {
+	Port int
+	Name string
+}
+
+func ExampleUnmarshalFS() {
+	// testdata/fs/config/app.dhall imports ./defaults.dhall and
+	// ../name.txt, which are read from configFS too
+	var c AppConfig
+	err := dhall.UnmarshalFS(configFS, "testdata/fs/config/app.dhall", &c)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v", c)
+	// Output:
+	// {Port:8080 Name:embedded}
+}
@@ -2,19 +2,25 @@ package imports
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"hash"
-	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"path"
+	"sort"
+	"time"
 
 	"github.com/wallyqs/dhall.go/binary"
 	"github.com/wallyqs/dhall.go/term"
 )
 
-// DhallCache is an interface for caching implementations.
+// DhallCache is an interface for caching implementations.  A Loader
+// serializes its calls to a DhallCache, unless it is one of this
+// package's caches, which are safe for concurrent use.
 type DhallCache interface {
 	// Fetch fetches a Term from the cache
 	Fetch(hash []byte) term.Term
@@ -22,63 +28,258 @@ type DhallCache interface {
 	Save(hash []byte, term term.Term)
 }
 
+// A ContextCache is a DhallCache which can give up fetching or saving
+// an entry once a context is done, such as one which makes network
+// requests.  A Loader fetches from and saves to a ContextCache with
+// the context of the Load.
+type ContextCache interface {
+	DhallCache
+	FetchContext(ctx context.Context, hash []byte) term.Term
+	SaveContext(ctx context.Context, hash []byte, term term.Term)
+}
+
+// fetchContext fetches the Term under hash from cache, with ctx if
+// cache is a ContextCache.
+func fetchContext(ctx context.Context, cache DhallCache, hash []byte) term.Term {
+	if c, ok := cache.(ContextCache); ok {
+		return c.FetchContext(ctx, hash)
+	}
+	return cache.Fetch(hash)
+}
+
+// saveContext saves e to cache under hash, with ctx if cache is a
+// ContextCache.
+func saveContext(ctx context.Context, cache DhallCache, hash []byte, e term.Term) {
+	if c, ok := cache.(ContextCache); ok {
+		c.SaveContext(ctx, hash, e)
+		return
+	}
+	cache.Save(hash, e)
+}
+
 // A LocalCache is a cache for normalized Dhall expressions, stored in
-// binary form.
+// binary form in a directory, in files named after their hashes.
 //
-// Note that a LocalCache has a shared hash.Hash instance, and as such
-// is not safe for concurrent use across goroutines.
+// A LocalCache is safe for concurrent use, by several goroutines and
+// by several processes sharing the same directory: entries are
+// written to a temporary file which is then renamed into place, so
+// that readers never see a partly written entry, and they are checked
+// against their hash when they are read.
 type LocalCache struct {
-	path string
-	hash hash.Hash
+	path    string
+	onError func(error)
+}
+
+// A CacheOption configures a LocalCache or a RemoteCache.
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	onError func(error)
+	client  *http.Client
 }
 
-// FIXME: we might want to move the hash.Hash implementation to a
-// context?
+func newCacheConfig(opts []CacheOption) cacheConfig {
+	c := cacheConfig{
+		onError: func(err error) { log.Printf("warning: %v\n", err) },
+		client:  defaultCacheClient,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// CacheErrorHandler makes the cache call onError with any error it
+// meets when reading or writing an entry, such as a corrupt entry or
+// a failed write.  A missing entry is not an error.  By default, the
+// errors are logged with the standard logger.  Either way, the cache
+// then carries on without the entry, as if it were not cached.
+func CacheErrorHandler(onError func(error)) CacheOption {
+	return func(c *cacheConfig) { c.onError = onError }
+}
 
 // NewLocalCache creates a new LocalCache, with the cache store at the
-// given path.
-func NewLocalCache(path string) LocalCache {
-	return LocalCache{path, sha256.New()}
+// given path.  The directory is created when the first entry is
+// saved, if it doesn't exist already.
+func NewLocalCache(path string, opts ...CacheOption) LocalCache {
+	return LocalCache{path: path, onError: newCacheConfig(opts).onError}
 }
 
 // Fetch searches the LocalCache for a term at the index given by
-// hash.  If the hash isn't in the cache, returns nil.
+// hash.  If the hash isn't in the cache, or its entry can't be read,
+// returns nil.  Fetching an entry updates its modification time,
+// which records when it was last used.
 func (l LocalCache) Fetch(hash []byte) term.Term {
-	// FIXME: don't swallow these errors, maybe?
-	hash16 := fmt.Sprintf("%x", hash)
-
-	reader, err := os.Open(path.Join(l.path, hash16))
+	name := l.entryPath(hash)
+	content, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
+		l.onError(err)
 		return nil
 	}
-
-	defer l.hash.Reset()
-	io.Copy(l.hash, reader)
-	if bytes.Compare(hash[2:], l.hash.Sum(nil)) != 0 {
-		log.Printf("warning: invalid cache entry for %x, ignoring\n", hash)
+	if sum := sha256.Sum256(content); len(hash) < 2 || !bytes.Equal(hash[2:], sum[:]) {
+		l.onError(fmt.Errorf("invalid cache entry for %x, ignoring", hash))
 		return nil
 	}
-
-	reader.Seek(0, io.SeekStart)
-
-	expr, err := binary.DecodeAsCbor(reader)
+	expr, err := binary.DecodeAsCbor(bytes.NewReader(content))
 	if err != nil {
-		log.Println(err)
+		l.onError(fmt.Errorf("invalid cache entry for %x: %w", hash, err))
 		return nil
 	}
+	// the cache may be read-only, so this may fail harmlessly
+	now := time.Now()
+	os.Chtimes(name, now, now)
 	return expr
 }
 
 // Save saves the given Term to the LocalCache at the given hash.
 func (l LocalCache) Save(hash []byte, e term.Term) {
-	hash16 := fmt.Sprintf("%x", hash)
-	file, err := os.Create(path.Join(l.path, hash16))
+	if err := l.save(hash, e); err != nil {
+		l.onError(fmt.Errorf("can't cache %x: %w", hash, err))
+	}
+}
+
+func (l LocalCache) save(hash []byte, e term.Term) error {
+	if err := os.MkdirAll(l.path, 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := binary.EncodeAsCbor(&buf, e); err != nil {
+		return err
+	}
+	return writeFileAtomically(l.path, fmt.Sprintf("%x", hash), buf.Bytes())
+}
+
+// writeFileAtomically writes data to the file name in dir, by way of
+// a temporary file which is renamed into place, so that the file is
+// never seen partly written.
+func writeFileAtomically(dir, name string, data []byte) error {
+	file, err := ioutil.TempFile(dir, ".tmp-")
 	if err != nil {
-		return
+		return err
+	}
+	// clean up if anything fails before the rename; afterwards,
+	// this fails harmlessly
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path.Join(dir, name))
+}
+
+func (l LocalCache) entryPath(hash []byte) string {
+	return path.Join(l.path, fmt.Sprintf("%x", hash))
+}
+
+// A CacheEntry describes an entry of a LocalCache.
+type CacheEntry struct {
+	// Hash is the multihash-encoded semantic hash under which the
+	// entry is stored.
+	Hash []byte
+	// Size is the size of the entry's file, in bytes.
+	Size int64
+	// LastUsed is when the entry was last saved or fetched.
+	LastUsed time.Time
+}
+
+// Entries returns the entries of the LocalCache, ordered by hash.
+// Files in its directory which are not named after a hash are
+// ignored.  If the directory doesn't exist, the cache is empty.
+func (l LocalCache) Entries() ([]CacheEntry, error) {
+	files, err := ioutil.ReadDir(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []CacheEntry
+	for _, file := range files {
+		hash, err := hex.DecodeString(file.Name())
+		if err != nil || len(hash) != 34 || hash[0] != 0x12 || hash[1] != 0x20 || !file.Mode().IsRegular() {
+			continue
+		}
+		entries = append(entries, CacheEntry{Hash: hash, Size: file.Size(), LastUsed: file.ModTime()})
+	}
+	return entries, nil
+}
+
+// Verify checks the contents of each entry of the LocalCache against
+// the hash it is stored under, and returns the entries which don't
+// match.
+func (l LocalCache) Verify() ([]CacheEntry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var corrupt []CacheEntry
+	for _, entry := range entries {
+		content, err := ioutil.ReadFile(l.entryPath(entry.Hash))
+		if os.IsNotExist(err) {
+			// removed since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sum := sha256.Sum256(content); !bytes.Equal(entry.Hash[2:], sum[:]) {
+			corrupt = append(corrupt, entry)
+		}
+	}
+	return corrupt, nil
+}
+
+// Remove removes the entry stored under hash from the LocalCache, if
+// there is one.
+func (l LocalCache) Remove(hash []byte) error {
+	err := os.Remove(l.entryPath(hash))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Prune removes the entries of the LocalCache which were last used
+// more than maxAge ago, and then the least recently used entries
+// until the total size of the rest is at most maxSize bytes.  A zero
+// maxAge or maxSize is no limit.  Prune returns the entries it
+// removed.
+func (l LocalCache) Prune(maxAge time.Duration, maxSize int64) ([]CacheEntry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	var removed []CacheEntry
+	for _, entry := range entries {
+		tooOld := maxAge > 0 && time.Since(entry.LastUsed) > maxAge
+		tooBig := maxSize > 0 && total > maxSize
+		if !tooOld && !tooBig {
+			break
+		}
+		if err := l.Remove(entry.Hash); err != nil {
+			return removed, err
+		}
+		total -= entry.Size
+		removed = append(removed, entry)
 	}
-	defer file.Close()
-	// ignores returned error
-	binary.EncodeAsCbor(file, e)
+	return removed, nil
 }
 
 // StandardCache is the standard DhallCache implementation.  It is a
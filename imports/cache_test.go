@@ -0,0 +1,178 @@
+package imports_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// multihashOfOne is the multihash of the binary encoding of 1, under
+// which it is cached.
+var multihashOfOne = func() []byte {
+	sum := sha256.Sum256([]byte{0x82, 0x0f, 0x01})
+	return append([]byte{0x12, 0x20}, sum[:]...)
+}()
+
+var _ = Describe("LocalCache", func() {
+	var dir string
+	var mu sync.Mutex
+	var errs []error
+	var cache LocalCache
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "dhall-cache")
+		Expect(err).ToNot(HaveOccurred())
+		dir = filepath.Join(dir, "nested", "dhall")
+		errs = nil
+		cache = NewLocalCache(dir, CacheErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}))
+	})
+	AfterEach(func() {
+		os.RemoveAll(filepath.Dir(filepath.Dir(dir)))
+	})
+
+	It("creates its directory when saving the first entry", func() {
+		cache.Save(multihashOfOne, NaturalLit(1))
+
+		Expect(errs).To(BeEmpty())
+		Expect(cache.Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+		entries, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal("1220" + "d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"))
+	})
+	It("misses missing entries silently", func() {
+		Expect(cache.Fetch(multihashOfOne)).To(BeNil())
+		Expect(errs).To(BeEmpty())
+	})
+	It("reports and ignores corrupt entries", func() {
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "1220d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"),
+			[]byte{0x82, 0x0f}, 0644)).To(Succeed())
+
+		Expect(cache.Fetch(multihashOfOne)).To(BeNil())
+		Expect(errs).To(ConsistOf(MatchError(ContainSubstring("invalid cache entry"))))
+	})
+	It("reports failures to save", func() {
+		Expect(os.MkdirAll(filepath.Dir(dir), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(dir, nil, 0644)).To(Succeed())
+
+		cache.Save(multihashOfOne, NaturalLit(1))
+
+		Expect(errs).To(ConsistOf(MatchError(ContainSubstring("can't cache"))))
+	})
+	It("is safe for concurrent readers and writers", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				cache.Save(multihashOfOne, NaturalLit(1))
+			}()
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				if expr := cache.Fetch(multihashOfOne); expr != nil {
+					Expect(expr).To(Equal(NaturalLit(1)))
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(errs).To(BeEmpty())
+		entries, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	Describe("managing entries", func() {
+		multihashOfTwo := func() []byte {
+			sum := sha256.Sum256([]byte{0x82, 0x0f, 0x02})
+			return append([]byte{0x12, 0x20}, sum[:]...)
+		}()
+		BeforeEach(func() {
+			cache.Save(multihashOfOne, NaturalLit(1))
+			cache.Save(multihashOfTwo, NaturalLit(2))
+			lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+			Expect(os.Chtimes(filepath.Join(dir, fmt.Sprintf("%x", multihashOfOne)), lastWeek, lastWeek)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not an entry"), 0644)).To(Succeed())
+		})
+
+		It("lists entries", func() {
+			entries, err := cache.Entries()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			for _, entry := range entries {
+				Expect(entry.Size).To(Equal(int64(3)))
+				if bytes.Equal(entry.Hash, multihashOfOne) {
+					Expect(entry.LastUsed).To(BeTemporally("~", time.Now().Add(-7*24*time.Hour), time.Minute))
+				} else {
+					Expect(entry.LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+				}
+			}
+		})
+		It("records when an entry was last fetched", func() {
+			cache.Fetch(multihashOfOne)
+			entries, err := cache.Entries()
+
+			Expect(err).ToNot(HaveOccurred())
+			for _, entry := range entries {
+				Expect(entry.LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+			}
+		})
+		It("lists no entries before the directory exists", func() {
+			entries, err := NewLocalCache(filepath.Join(dir, "missing")).Entries()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+		It("verifies entries against their hashes", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%x", multihashOfTwo)), []byte{0x82, 0x0f, 0x03}, 0644)).To(Succeed())
+			corrupt, err := cache.Verify()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(corrupt).To(HaveLen(1))
+			Expect(corrupt[0].Hash).To(Equal(multihashOfTwo))
+
+			Expect(cache.Remove(corrupt[0].Hash)).To(Succeed())
+			Expect(cache.Verify()).To(BeEmpty())
+			Expect(cache.Entries()).To(HaveLen(1))
+		})
+		It("prunes entries by age", func() {
+			removed, err := cache.Prune(24*time.Hour, 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(HaveLen(1))
+			Expect(removed[0].Hash).To(Equal(multihashOfOne))
+			Expect(cache.Fetch(multihashOfOne)).To(BeNil())
+			Expect(cache.Fetch(multihashOfTwo)).To(Equal(NaturalLit(2)))
+		})
+		It("prunes the least recently used entries by size", func() {
+			removed, err := cache.Prune(0, 5)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(HaveLen(1))
+			Expect(removed[0].Hash).To(Equal(multihashOfOne))
+
+			removed, err = cache.Prune(0, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(HaveLen(1))
+			Expect(cache.Entries()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package imports
+
+import (
+	"context"
+
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// A Dependency is a node in the graph of imports of an expression.
+type Dependency struct {
+	// Fetchable is the imported location, chained onto the location
+	// of the importing expression, as it was fetched.
+	Fetchable Fetchable
+	Mode      ImportMode
+	// Hash is the multihash-encoded semantic hash of the imported
+	// expression.  It is nil for `as Location` imports.
+	Hash []byte
+	// Children are the imports of the imported expression.
+	Children []*Dependency
+}
+
+// Dependencies resolves the imports of e, like Load, and returns the
+// graph of its imports: the imports in e, with the imports they have
+// in turn as their Children.  Imports are chained exactly as Load
+// chains them.  Of the two sides of an import alternative (`?`), only
+// the one which resolved is included.  Imports are fetched even if
+// they are cached, so that the graph is complete.
+func (l *Loader) Dependencies(e Term, ancestors ...Fetchable) ([]*Dependency, error) {
+	return l.DependenciesContext(context.Background(), e, ancestors...)
+}
+
+// DependenciesContext is like Dependencies, but stops resolving
+// imports and returns ctx's error if ctx is done before resolution
+// finishes.
+func (l *Loader) DependenciesContext(ctx context.Context, e Term, ancestors ...Fetchable) ([]*Dependency, error) {
+	uncached := *l
+	uncached.cache = NoCache{}
+	r := newResolver(&uncached, ctx)
+	r.dependencies = true
+	_, deps, err := r.load(e, ancestors...)
+	if err != nil {
+		return nil, err
+	}
+	if deps == nil {
+		deps = []*Dependency{}
+	}
+	return deps, nil
+}
@@ -0,0 +1,76 @@
+package imports_test
+
+import (
+	"testing/fstest"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	. "github.com/wallyqs/dhall.go/internal"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dependencies", func() {
+	var loader *Loader
+	BeforeEach(func() {
+		loader = NewLoader(WithCache(NoCache{}), WithFS(fstest.MapFS{
+			"main.dhall":       {Data: []byte(`{ a = ./sub/a.dhall, b = ./b.txt as Text, c = ./b.txt as Location }`)},
+			"sub/a.dhall":      {Data: []byte(`./nested.dhall ++ (env:UNSET ? "")`)},
+			"sub/nested.dhall": {Data: []byte(`"a"`)},
+			"b.txt":            {Data: []byte(`b`)},
+		}), WithEnv(map[string]string{}))
+	})
+	dependencies := func(source string) []*Dependency {
+		expr, err := parser.Parse("root.dhall", []byte(source))
+		Expect(err).ToNot(HaveOccurred())
+		deps, err := loader.Dependencies(expr, LocalFile("root.dhall"))
+		Expect(err).ToNot(HaveOccurred())
+		return deps
+	}
+	It("returns the graph of imports, chained as Load chains them", func() {
+		deps := dependencies("./main.dhall")
+		Expect(deps).To(HaveLen(1))
+		Expect(deps[0].Fetchable).To(Equal(LocalFile("main.dhall")))
+
+		children := deps[0].Children
+		Expect(children).To(HaveLen(3))
+		Expect(children[0].Fetchable).To(Equal(LocalFile("sub/a.dhall")))
+		Expect(children[0].Mode).To(Equal(Code))
+		Expect(children[1].Fetchable).To(Equal(LocalFile("b.txt")))
+		Expect(children[1].Mode).To(Equal(RawText))
+		Expect(children[2].Mode).To(Equal(Location))
+
+		Expect(children[0].Children).To(HaveLen(1))
+		Expect(children[0].Children[0].Fetchable).To(Equal(LocalFile("sub/nested.dhall")))
+	})
+	It("records the semantic hash of each import", func() {
+		deps := dependencies("./b.txt as Text")
+		Expect(deps[0].Hash).To(HaveLen(34))
+		Expect(deps[0].Hash[:2]).To(Equal([]byte{0x12, 0x20}))
+		Expect(dependencies("./b.txt as Location")[0].Hash).To(BeNil())
+	})
+	It("leaves out the failed side of an import alternative", func() {
+		deps := dependencies(`env:UNSET ? ./b.txt as Text`)
+		Expect(deps).To(HaveLen(1))
+		Expect(deps[0].Fetchable).To(Equal(LocalFile("b.txt")))
+	})
+	It("includes the imports of cached imports", func() {
+		loader = NewLoader(WithCache(oneCache{}), WithFS(fstest.MapFS{
+			"one.dhall":     {Data: []byte(`./sub/one.dhall`)},
+			"sub/one.dhall": {Data: []byte(`1`)},
+		}))
+		deps := dependencies("./one.dhall sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15")
+		Expect(deps).To(HaveLen(1))
+		Expect(deps[0].Children).To(HaveLen(1))
+		Expect(deps[0].Children[0].Fetchable).To(Equal(LocalFile("sub/one.dhall")))
+	})
+	It("returns no dependencies for an expression without imports", func() {
+		Expect(dependencies("1")).To(BeEmpty())
+	})
+	It("fails if an import can't be resolved", func() {
+		_, err := loader.Dependencies(NewLocalImport("missing.dhall", Code))
+		Expect(err).To(HaveOccurred())
+	})
+})
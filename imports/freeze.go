@@ -0,0 +1,210 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/wallyqs/dhall.go/binary"
+	"github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// A FreezeOption configures Freeze.
+type FreezeOption func(*freezer)
+
+// FreezeAll makes Freeze pin local and environment variable imports,
+// as well as remote imports.
+func FreezeAll() FreezeOption {
+	return func(f *freezer) { f.all = true }
+}
+
+// FreezeWithFallback makes Freeze pin each import as
+//
+//	missing sha256:… ? import
+//
+// so that the pinned expression is read from the cache if it is
+// there, and the import is resolved as before otherwise.
+func FreezeWithFallback() FreezeOption {
+	return func(f *freezer) { f.fallback = true }
+}
+
+// Freeze is like Loader.Freeze, using the standard cache.
+func Freeze(filename string, src []byte, opts ...FreezeOption) ([]byte, error) {
+	return NewLoader().Freeze(filename, src, opts...)
+}
+
+// Freeze pins the remote imports in src, the Dhall source text of
+// filename, by adding the `sha256:` semantic hash of each.  It
+// returns src with only the imports rewritten, keeping the rest of
+// its formatting.  Imports which are already pinned, and `as
+// Location` imports, are left as they are, as is an import on one
+// side of a `?` which can't be resolved.  Relative imports are
+// resolved relative to filename, and the pinned expressions are saved
+// to the Loader's cache.
+func (l *Loader) Freeze(filename string, src []byte, opts ...FreezeOption) ([]byte, error) {
+	return l.FreezeContext(context.Background(), filename, src, opts...)
+}
+
+// FreezeContext is like Freeze, but stops resolving imports and
+// returns ctx's error if ctx is done before freezing finishes.
+func (l *Loader) FreezeContext(ctx context.Context, filename string, src []byte, opts ...FreezeOption) ([]byte, error) {
+	expr, err := parser.Parse(filename, src, parser.WithSpans())
+	if err != nil {
+		return nil, err
+	}
+	f := &freezer{resolver: newResolver(l, ctx), src: src, root: expr}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if err := f.freeze(expr, LocalFile(filename)); err != nil {
+		return nil, err
+	}
+	// apply the edits from the end, so that their offsets stay valid
+	sort.Slice(f.edits, func(i, j int) bool {
+		return f.edits[i].start > f.edits[j].start
+	})
+	out := append([]byte{}, src...)
+	for _, e := range f.edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out, nil
+}
+
+// A freezer holds the state of a single Freeze.
+type freezer struct {
+	*resolver
+	all      bool
+	fallback bool
+
+	src   []byte
+	root  Term
+	edits []edit
+}
+
+// An edit replaces the source text from start to end with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+func (f *freezer) freeze(t Term, here Fetchable) error {
+	if n, ok := t.(Note); ok {
+		if i, ok := stripNote(n.Term).(Import); ok {
+			return f.freezeImport(n, i, here)
+		}
+	}
+	if op, ok := stripNote(t).(Op); ok && op.OpCode == ImportAltOp {
+		if i, ok := stripNote(op.L).(Import); ok && i.Fetchable == (Missing{}) && i.Hash != nil {
+			// already frozen with a fallback
+			return nil
+		}
+		return f.freezeAlternatives(op, here)
+	}
+	_, err := MaybeTransformSubexprs(t, func(t Term) (Term, error) {
+		return t, f.freeze(t, here)
+	})
+	return err
+}
+
+// freezeAlternatives freezes each side of a `?` independently,
+// leaving a side as it is if it can't be resolved.  It fails only if
+// neither side can be frozen.
+func (f *freezer) freezeAlternatives(op Op, here Fetchable) error {
+	edits := len(f.edits)
+	errL := f.freeze(op.L, here)
+	if errL != nil {
+		f.edits = f.edits[:edits]
+	}
+	edits = len(f.edits)
+	errR := f.freeze(op.R, here)
+	if errR != nil {
+		f.edits = f.edits[:edits]
+		if errL != nil {
+			return errL
+		}
+	}
+	return nil
+}
+
+func (f *freezer) freezeImport(n Note, i Import, here Fetchable) error {
+	if i.Hash != nil || i.ImportMode == Location || i.Fetchable == (Missing{}) {
+		return nil
+	}
+	if _, ok := i.Fetchable.(RemoteFile); !ok && !f.all {
+		return nil
+	}
+	expr, _, err := f.load(Import{ImportHashed: ImportHashed{Fetchable: i.Fetchable}, ImportMode: i.ImportMode}, here)
+	if err != nil {
+		return err
+	}
+	val := core.Eval(expr)
+	hash, err := binary.SemanticHash(val)
+	if err != nil {
+		return err
+	}
+	saveContext(f.ctx, f.cache, hash, core.QuoteAlphaNormal(val))
+
+	hashText := fmt.Sprintf("sha256:%x", hash[2:])
+	start, end := f.importText(n.Span)
+	if f.fallback {
+		text := "missing " + hashText + " ? " + string(f.src[start:end])
+		// parenthesize the alternative, unless the import is
+		// already in parentheses, or all there is
+		if start == n.Span.Start.Offset && n.Span != f.rootSpan() {
+			text = "(" + text + ")"
+		}
+		f.edits = append(f.edits, edit{start, end, text})
+		return nil
+	}
+	// the hash goes before the import mode, if any
+	at := end
+	if loc := importModeSuffix.FindIndex(f.src[start:end]); loc != nil {
+		at = start + loc[0]
+	}
+	f.edits = append(f.edits, edit{at, at, " " + hashText})
+	return nil
+}
+
+var importModeSuffix = regexp.MustCompile(`\s+as\s+(Text|Bytes|Location)\s*$`)
+
+// importText returns the offsets of the start and end of the source
+// text of span, an import's, without the whitespace and parentheses
+// around it.
+func (f *freezer) importText(span Span) (int, int) {
+	start, end := span.Start.Offset, span.End.Offset
+	for {
+		for start < end && isBlank(f.src[start]) {
+			start++
+		}
+		for end > start && isBlank(f.src[end-1]) {
+			end--
+		}
+		if end-start < 2 || f.src[start] != '(' || f.src[end-1] != ')' {
+			return start, end
+		}
+		start, end = start+1, end-1
+	}
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// rootSpan returns the Span of the whole expression being frozen.
+func (f *freezer) rootSpan() Span {
+	n, _ := f.root.(Note)
+	return n.Span
+}
+
+func stripNote(t Term) Term {
+	for {
+		n, ok := t.(Note)
+		if !ok {
+			return t
+		}
+		t = n.Term
+	}
+}
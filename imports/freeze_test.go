@@ -0,0 +1,90 @@
+package imports_test
+
+import (
+	"net/http"
+	"testing/fstest"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+// the semantic hash of `1`
+const hashOfOne = "sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"
+
+var _ = Describe("Freeze", func() {
+	var server *ghttp.Server
+	var loader *Loader
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/one.dhall", ghttp.RespondWith(http.StatusOK, "1"))
+		loader = NewLoader(WithCache(NoCache{}), WithFS(fstest.MapFS{
+			"dir/one.dhall": {Data: []byte("1")},
+		}))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	freeze := func(src string, opts ...FreezeOption) string {
+		out, err := loader.Freeze("dir/main.dhall", []byte(src), opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return string(out)
+	}
+	It("pins remote imports, keeping the rest of the source", func() {
+		src := "-- comment\n{ a =  " + server.URL() + "/one.dhall, b = ./one.dhall }\n"
+		Expect(freeze(src)).To(Equal(
+			"-- comment\n{ a =  " + server.URL() + "/one.dhall " + hashOfOne + ", b = ./one.dhall }\n"))
+	})
+	It("pins local imports, relative to the file, with FreezeAll", func() {
+		Expect(freeze("./one.dhall + 1", FreezeAll())).To(Equal(
+			"./one.dhall " + hashOfOne + " + 1"))
+	})
+	It("keeps the parentheses around an import", func() {
+		Expect(freeze("(./one.dhall).a (./one.dhall)", FreezeAll())).To(Equal(
+			"(./one.dhall " + hashOfOne + ").a (./one.dhall " + hashOfOne + ")"))
+		Expect(freeze("f (./one.dhall)", FreezeAll(), FreezeWithFallback())).To(Equal(
+			"f (missing " + hashOfOne + " ? ./one.dhall)"))
+	})
+	It("keeps the `using` headers of an import as they are", func() {
+		src := server.URL() + `/one.dhall using [ { mapKey = "X", mapValue = "1" } ] as Text`
+		Expect(freeze(src)).To(Equal(server.URL() + `/one.dhall using [ { mapKey = "X", mapValue = "1" } ]` +
+			" sha256:935677385986713bb54a715dad0b158738a30b524d329f780550042134023d82 as Text"))
+	})
+	It("keeps the import mode", func() {
+		Expect(freeze("./one.dhall as Text", FreezeAll())).To(Equal(
+			"./one.dhall sha256:935677385986713bb54a715dad0b158738a30b524d329f780550042134023d82 as Text"))
+	})
+	It("leaves pinned and `as Location` imports alone", func() {
+		src := "[ ./one.dhall " + hashOfOne + ", ./one.dhall as Location ]"
+		Expect(freeze(src, FreezeAll())).To(Equal(src))
+	})
+	It("adds a fallback with FreezeWithFallback", func() {
+		Expect(freeze("./one.dhall", FreezeAll(), FreezeWithFallback())).To(Equal(
+			"missing " + hashOfOne + " ? ./one.dhall"))
+		frozen := freeze("./one.dhall + 1", FreezeAll(), FreezeWithFallback())
+		Expect(frozen).To(Equal(
+			"(missing " + hashOfOne + " ? ./one.dhall) + 1"))
+		Expect(freeze(frozen, FreezeAll(), FreezeWithFallback())).To(Equal(frozen))
+	})
+	It("produces imports which pass their integrity checks", func() {
+		frozen := freeze(server.URL()+"/one.dhall + ./one.dhall", FreezeAll())
+		expr, err := parser.Parse("dir/main.dhall", []byte(frozen))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loader.Load(expr, LocalFile("dir/main.dhall"))).To(Equal(
+			Op{OpCode: PlusOp, L: NaturalLit(1), R: NaturalLit(1)}))
+	})
+	It("fails if an import can't be resolved", func() {
+		_, err := loader.Freeze("dir/main.dhall", []byte("./missing.dhall"), FreezeAll())
+		Expect(err).To(HaveOccurred())
+	})
+	It("freezes the resolvable sides of an alternative", func() {
+		Expect(freeze("{ x = env:DHALL_GO_FREEZE_UNSET ? ./one.dhall }", FreezeAll())).To(Equal(
+			"{ x = env:DHALL_GO_FREEZE_UNSET ? ./one.dhall " + hashOfOne + " }"))
+		_, err := loader.Freeze("dir/main.dhall", []byte("env:DHALL_GO_FREEZE_UNSET ? ./missing.dhall"), FreezeAll())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,188 @@
+package imports
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"runtime"
+
+	"github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// The user-supplied headers configuration maps origins, in the form
+// "host:port", to the headers to send to that origin.
+var userHeadersType = core.ListOf{Type: core.RecordType{
+	"mapKey":   core.Text,
+	"mapValue": headersType,
+}}
+
+// DhallConfigDir returns the path to the Dhall configuration
+// directory, which may contain a headers.dhall file.  As the standard
+// requires, it is $XDG_CONFIG_HOME/dhall, or ~/.config/dhall if
+// XDG_CONFIG_HOME is unset, on every platform but Windows, where it is
+// %AppData%\dhall.
+func DhallConfigDir() (string, error) {
+	return dhallConfigDir(os.LookupEnv)
+}
+
+// dhallConfigDir is DhallConfigDir, with the environment variables
+// which lookupEnv returns.
+func dhallConfigDir(lookupEnv func(name string) (string, bool)) (string, error) {
+	getenv := func(name string) string {
+		val, _ := lookupEnv(name)
+		return val
+	}
+	if runtime.GOOS == "windows" {
+		configDir := getenv("AppData")
+		if configDir == "" {
+			return "", errors.New("%AppData% is not defined")
+		}
+		return path.Join(configDir, "dhall"), nil
+	}
+	configDir := getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = getenv("HOME")
+		if configDir == "" {
+			return "", errors.New("neither $XDG_CONFIG_HOME nor $HOME are defined")
+		}
+		configDir += "/.config"
+	} else if !path.IsAbs(configDir) {
+		return "", errors.New("path in $XDG_CONFIG_HOME is relative")
+	}
+	return path.Join(configDir, "dhall"), nil
+}
+
+// userHeadersFor returns the user-supplied headers for the origin of
+// remote, or nil if there are none.
+func (r *resolver) userHeadersFor(remote RemoteFile) (Term, error) {
+	if r.noUserHeaders {
+		return nil, nil
+	}
+	r.userHeadersOnce.Do(func() {
+		r.userHeaders, r.userHeadersErr = r.loadUserHeaders()
+	})
+	if r.userHeadersErr != nil {
+		return nil, r.userHeadersErr
+	}
+	config, ok := r.userHeaders.(NonEmptyList)
+	if !ok {
+		return nil, nil
+	}
+	key := originKey(remote)
+	var headers NonEmptyList
+	for _, entry := range config {
+		entry := entry.(RecordLit)
+		if origin, _ := entry["mapKey"].(TextLit); origin.Suffix != key {
+			continue
+		}
+		if list, ok := entry["mapValue"].(NonEmptyList); ok {
+			headers = append(headers, list...)
+		}
+	}
+	if headers == nil {
+		return nil, nil
+	}
+	return headers, nil
+}
+
+// loadUserHeaders reads the user-supplied headers configuration from
+// the DHALL_HEADERS environment variable or, if that is unset, from
+// headers.dhall in the Dhall configuration directory.  It returns nil
+// if neither is present.  Both are read with the Loader's fetcher, so
+// that a Loader with its own environment or filesystem doesn't see
+// the process's.
+func (r *resolver) loadUserHeaders() (Term, error) {
+	var here Fetchable
+	var source []byte
+	if env, ok := r.fetcher.LookupEnv("DHALL_HEADERS"); ok {
+		here = EnvVar("DHALL_HEADERS")
+		source = []byte(env)
+	} else {
+		configDir, err := dhallConfigDir(r.fetcher.LookupEnv)
+		if err != nil {
+			return nil, nil
+		}
+		file := path.Join(configDir, "headers.dhall")
+		source, err = r.fetcher.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		here = LocalFile(file)
+	}
+	expr, err := parser.Parse(here.String(), source, parser.WithSpans())
+	if err != nil {
+		return nil, err
+	}
+	// remote imports in the configuration are fetched without
+	// user-supplied headers
+	configResolver := &resolver{Loader: r.Loader, ctx: r.ctx, sem: r.sem, cache: r.cache, memo: r.memo, noUserHeaders: true}
+	expr, _, err = configResolver.load(expr, here)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := core.TypeOfWithLimits(r.ctx, expr, r.limits)
+	if err != nil {
+		return nil, err
+	}
+	if !core.AlphaEquivalent(typ, userHeadersType) {
+		return nil, errors.New(here.String() + " must have type List { mapKey : Text, mapValue : List { mapKey : Text, mapValue : Text } }")
+	}
+	return core.NormalizeWithLimits(r.ctx, expr, r.limits)
+}
+
+// originKey returns the key for remote's origin in the user-supplied
+// headers configuration: its host and port, with the port defaulting
+// to that of the URL's scheme.
+func originKey(remote RemoteFile) string {
+	u, err := url.Parse(remote.String())
+	if err != nil {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if remote.IsPlainHTTP() {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// mergeHeaders combines user-supplied headers with the headers from a
+// `using` clause.  User-supplied headers take precedence over headers
+// of the same name from the `using` clause, as the standard requires,
+// so that Dhall source can't replace the user's credentials.
+func mergeHeaders(userHeaders, usingHeaders Term) Term {
+	if userHeaders == nil {
+		return usingHeaders
+	}
+	user := userHeaders.(NonEmptyList)
+	overridden := make(map[string]bool, len(user))
+	for _, entry := range user {
+		overridden[headerName(entry)] = true
+	}
+	var merged NonEmptyList
+	using, _ := usingHeaders.(NonEmptyList)
+	for _, entry := range using {
+		if !overridden[headerName(entry)] {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, user...)
+}
+
+// headerName returns the canonical name of the header in a normalized
+// { mapKey, mapValue } record.
+func headerName(entry Term) string {
+	name, _ := entry.(RecordLit)["mapKey"].(TextLit)
+	return http.CanonicalHeaderKey(name.Suffix)
+}
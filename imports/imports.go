@@ -2,7 +2,10 @@ package imports
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/wallyqs/dhall.go/binary"
 	"github.com/wallyqs/dhall.go/core"
@@ -20,107 +23,489 @@ func Load(e Term, ancestors ...Fetchable) (Term, error) {
 	return LoadWith(cache, e, ancestors...)
 }
 
+// LoadContext is like Load, but stops resolving imports and returns
+// ctx's error if ctx is done before resolution finishes.  ctx is also
+// used for any HTTP requests made to fetch remote imports.
+func LoadContext(ctx context.Context, e Term, ancestors ...Fetchable) (Term, error) {
+	cache, err := StandardCache()
+	if err != nil {
+		return nil, err
+	}
+	return NewLoader(WithCache(cache)).LoadContext(ctx, e, ancestors...)
+}
+
 // LoadWith takes a Term and resolves all imports, using cache for
 // saving and fetching imports
 func LoadWith(cache DhallCache, e Term, ancestors ...Fetchable) (Term, error) {
-	switch e := e.(type) {
-	case Import:
-		here := e.Fetchable
-		origin := term.NullOrigin
-		if len(ancestors) >= 1 {
-			origin = ancestors[len(ancestors)-1].Origin()
+	return NewLoader(WithCache(cache)).Load(e, ancestors...)
+}
 
-			var err error
-			here, err = here.ChainOnto(ancestors[len(ancestors)-1])
-			if err != nil {
-				return nil, err
-			}
-		}
-		if e.ImportMode == Location {
-			return here.AsLocation(), nil
+// A resolver holds the state of a single import resolution.  Its
+// imports are resolved concurrently, each in its own goroutine, but
+// the fetching and evaluation of imports is bounded by its worker
+// pool.
+type resolver struct {
+	*Loader
+	ctx context.Context
+
+	// the worker pool: one token per import which may be fetched or
+	// evaluated at once; nil if imports are resolved one at a time
+	sem chan struct{}
+	// the Loader's cache, made safe for concurrent use
+	cache DhallCache
+	// the imports resolved so far
+	memo *memo
+
+	// the user-supplied headers configuration, from DHALL_HEADERS
+	// or headers.dhall; loaded on first use
+	userHeaders     Term
+	userHeadersErr  error
+	userHeadersOnce sync.Once
+	// set while resolving the user-supplied headers themselves,
+	// which must not depend on themselves
+	noUserHeaders bool
+
+	// if set, the resolver returns the Dependencies of the imports
+	// it resolves; see Dependencies
+	dependencies bool
+	// if set, called with the vendor key and source of each remote
+	// import the resolver fetches; see Vendor
+	onFetch func(vendorKey, content string)
+}
+
+func newResolver(l *Loader, ctx context.Context) *resolver {
+	r := &resolver{Loader: l, ctx: ctx, cache: concurrentCache(l.cache), memo: newMemo()}
+	if l.concurrency > 1 {
+		r.sem = make(chan struct{}, l.concurrency)
+	}
+	return r
+}
+
+// A task is the resolution of an import, or of an import alternative,
+// which may still be running.
+type task struct {
+	done chan struct{}
+	expr Term
+	deps []*Dependency
+	err  error
+}
+
+// load resolves the imports in e, and returns e with them replaced
+// by their resolutions, along with their Dependencies if the resolver
+// is recording them.  The imports are resolved concurrently, but the
+// result is the same as resolving them one at a time, in the order
+// they appear in e: in particular, if several imports fail to
+// resolve, the error is that of the first of them.
+func (r *resolver) load(e Term, ancestors ...Fetchable) (Term, []*Dependency, error) {
+	var tasks []*task
+	if err := r.start(e, ancestors, &tasks); err != nil {
+		return nil, nil, err
+	}
+	// wait for every task, even after one fails, so that none
+	// outlives the resolution
+	var err error
+	var deps []*Dependency
+	for _, t := range tasks {
+		<-t.done
+		if err == nil {
+			err = t.err
 		}
+		deps = append(deps, t.deps...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	expr, err := r.fill(e, &tasks)
+	if err != nil {
+		return nil, nil, err
+	}
+	return expr, deps, nil
+}
 
-		for _, ancestor := range ancestors {
-			if ancestor == here {
-				return nil, fmt.Errorf("Detected import cycle in %s", ancestor)
-			}
+// start starts a task for each import and import alternative in e,
+// in order, and appends them to tasks.  When imports are resolved one
+// at a time, the tasks have finished when start returns, and start
+// stops at, and returns the error of, the first which fails.
+func (r *resolver) start(e Term, ancestors []Fetchable, tasks *[]*task) error {
+	var t *task
+	switch e := e.(type) {
+	case Import:
+		t = r.spawn(func() (Term, []*Dependency, error) {
+			return r.loadImport(e, ancestors...)
+		})
+	case Op:
+		if e.OpCode != ImportAltOp {
+			return r.startSubexprs(e, ancestors, tasks)
 		}
-		if e.Hash != nil {
-			// fetch from cache if available
-			if expr := cache.Fetch(e.Hash); expr != nil {
-				return expr, nil
+		t = r.spawn(func() (Term, []*Dependency, error) {
+			resolvedL, deps, err := r.load(e.L, ancestors...)
+			if err == nil {
+				return resolvedL, deps, nil
 			}
+			return r.load(e.R, ancestors...)
+		})
+	default:
+		// Const, NaturalLit, etc
+		return r.startSubexprs(e, ancestors, tasks)
+	}
+	*tasks = append(*tasks, t)
+	if r.sem == nil {
+		return t.err
+	}
+	return nil
+}
+
+func (r *resolver) startSubexprs(e Term, ancestors []Fetchable, tasks *[]*task) error {
+	_, err := term.MaybeTransformSubexprs(e, func(t Term) (Term, error) {
+		return t, r.start(t, ancestors, tasks)
+	})
+	return err
+}
+
+// fill replaces the imports and import alternatives in e with the
+// results of tasks, which start started for them, in order.
+func (r *resolver) fill(e Term, tasks *[]*task) (Term, error) {
+	switch e := e.(type) {
+	case Import:
+	case Op:
+		if e.OpCode != ImportAltOp {
+			return term.MaybeTransformSubexprs(e, func(t Term) (Term, error) {
+				return r.fill(t, tasks)
+			})
 		}
-		imports := append(ancestors, here)
-		content, err := here.Fetch(origin)
+	default:
+		return term.MaybeTransformSubexprs(e, func(t Term) (Term, error) {
+			return r.fill(t, tasks)
+		})
+	}
+	t := (*tasks)[0]
+	*tasks = (*tasks)[1:]
+	return t.expr, nil
+}
+
+// spawn runs run as a task: in a new goroutine, or right away if
+// imports are resolved one at a time.
+func (r *resolver) spawn(run func() (Term, []*Dependency, error)) *task {
+	t := &task{done: make(chan struct{})}
+	if r.sem == nil {
+		t.expr, t.deps, t.err = run()
+		close(t.done)
+		return t
+	}
+	go func() {
+		defer close(t.done)
+		t.expr, t.deps, t.err = run()
+	}()
+	return t
+}
+
+// acquire takes a token from the worker pool, waiting until one is
+// free, and release gives it back.  A task never waits for another
+// while it holds a token.
+func (r *resolver) acquire() error {
+	if err := r.ctx.Err(); err != nil || r.sem == nil {
+		return err
+	}
+	select {
+	case r.sem <- struct{}{}:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+func (r *resolver) release() {
+	if r.sem != nil {
+		<-r.sem
+	}
+}
+
+// loadImport resolves e, and returns its Dependency, followed by
+// those of its `using` headers, if the resolver is recording them.
+func (r *resolver) loadImport(e Import, ancestors ...Fetchable) (Term, []*Dependency, error) {
+	cache := r.cache
+	here := e.Fetchable
+	origin := term.NullOrigin
+	if len(ancestors) >= 1 {
+		origin = ancestors[len(ancestors)-1].Origin()
+
+		var err error
+		here, err = here.ChainOnto(ancestors[len(ancestors)-1])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		var expr Term
-		if e.ImportMode == RawText {
-			expr = PlainText(content)
-		} else {
-			// dynamicExpr may contain more imports
-			dynamicExpr, err := parser.Parse(here.String(), []byte(content))
-			if err != nil {
-				return nil, err
-			}
+	}
+	var dep *Dependency
+	var deps []*Dependency
+	if r.dependencies {
+		dep = &Dependency{Fetchable: here, Mode: e.ImportMode}
+		deps = []*Dependency{dep}
+	}
+	if e.ImportMode == Location {
+		return here.AsLocation(), deps, nil
+	}
 
-			// recursively load any more imports
-			expr, err = LoadWith(cache, dynamicExpr, imports...)
-			if err != nil {
-				return nil, err
+	for _, ancestor := range ancestors {
+		// compare locations, since RemoteFiles with headers
+		// are not comparable
+		if ancestor.String() == here.String() {
+			return nil, nil, fmt.Errorf("Detected import cycle in %s", ancestor)
+		}
+	}
+	if err := r.policy.check(here, ancestors, !r.ownReadFile); err != nil {
+		return nil, nil, err
+	}
+	if e.Hash != nil {
+		// fetch from cache if available
+		if expr := fetchContext(r.ctx, cache, e.Hash); expr != nil {
+			if dep != nil {
+				dep.Hash = e.Hash
 			}
-
-			// ensure that expr typechecks in empty context
-			_, err = core.TypeOf(expr)
+			return expr, deps, nil
+		}
+	}
+	var vendorKey string
+	if remote, ok := here.(RemoteFile); ok {
+		var using Term
+		if remote.Headers() != nil {
+			var headerDeps []*Dependency
+			var err error
+			using, headerDeps, err = r.loadHeaders(remote.Headers(), ancestors...)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
+			deps = append(deps, headerDeps...)
+		}
+		var err error
+		vendorKey, err = vendorKeyOf(remote, using)
+		if err != nil {
+			return nil, nil, err
 		}
+		here, err = r.withUserHeaders(remote, using)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	expr, children, err := r.resolve(here, vendorKey, origin, e.ImportMode, ancestors)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dep != nil {
+		dep.Children = children
+	}
+	exprVal := core.Eval(expr)
 
-		// evaluate expression
-		exprVal := core.Eval(expr)
-		expr = core.Quote(exprVal)
+	// check hash, if supplied
+	if e.Hash != nil {
+		actualHash, err := binary.SemanticHash(exprVal)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !bytes.Equal(e.Hash, actualHash[:]) {
+			return nil, nil, fmt.Errorf("Failed integrity check: expected %x but saw %x", e.Hash, actualHash)
+		}
+		// store in cache
+		saveContext(r.ctx, cache, actualHash, core.QuoteAlphaNormal(exprVal))
+	}
+	if dep != nil {
+		dep.Hash, err = binary.SemanticHash(exprVal)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return expr, deps, nil
+}
 
-		// check hash, if supplied
-		if e.Hash != nil {
-			actualHash, err := binary.SemanticHash(exprVal)
-			if err != nil {
-				return nil, err
-			}
-			if !bytes.Equal(e.Hash, actualHash[:]) {
-				return nil, fmt.Errorf("Failed integrity check: expected %x but saw %x", e.Hash, actualHash)
-			}
-			// store in cache
-			cache.Save(actualHash, core.QuoteAlphaNormal(exprVal))
+// resolve fetches here and evaluates it, resolving any imports in it
+// in turn, or returns its resolution from earlier in the Load.
+// vendorKey is the key of a remote import in the Loader's Vendor.
+func (r *resolver) resolve(here Fetchable, vendorKey, origin string, mode ImportMode, ancestors []Fetchable) (Term, []*Dependency, error) {
+	key := keyOf(here, origin, mode)
+	var within *memoKey
+	if len(ancestors) >= 1 {
+		parentOrigin := term.NullOrigin
+		if len(ancestors) >= 2 {
+			parentOrigin = ancestors[len(ancestors)-2].Origin()
 		}
-		return expr, nil
-	case Op:
-		if e.OpCode == ImportAltOp {
-			resolvedL, err := LoadWith(cache, e.L, ancestors...)
-			if err == nil {
-				return resolvedL, nil
-			}
-			resolvedR, err := LoadWith(cache, e.R, ancestors...)
-			if err != nil {
-				return nil, err
-			}
-			return resolvedR, nil
+		parent := keyOf(ancestors[len(ancestors)-1], parentOrigin, Code)
+		within = &parent
+	}
+	entry, owned := r.memo.claim(key, within)
+	if entry != nil && !owned {
+		return entry.expr, entry.children, nil
+	}
+	expr, children, err := r.evaluate(here, vendorKey, origin, mode, ancestors)
+	if owned {
+		r.memo.finish(key, entry, expr, children, err)
+	}
+	return expr, children, err
+}
+
+// evaluate fetches here and returns its normal form, along with the
+// Dependencies of the imports in it, if the resolver is recording
+// them.
+func (r *resolver) evaluate(here Fetchable, vendorKey, origin string, mode ImportMode, ancestors []Fetchable) (Term, []*Dependency, error) {
+	if err := r.acquire(); err != nil {
+		return nil, nil, err
+	}
+	imports := append(ancestors[:len(ancestors):len(ancestors)], here)
+	content, err := r.fetch(here, vendorKey, origin)
+	r.release()
+	var policyErr *PolicyError
+	if errors.As(err, &policyErr) {
+		// a redirect which the policy forbids
+		return nil, nil, &PolicyError{Chain: imports, Reason: policyErr.Reason}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var expr Term
+	var children []*Dependency
+	switch mode {
+	case RawText:
+		expr = PlainText(content)
+	case RawBytes:
+		expr = BytesLit(content)
+	default:
+		// dynamicExpr may contain more imports
+		dynamicExpr, err := parser.Parse(here.String(), []byte(content), parser.WithSpans())
+		if err != nil {
+			return nil, nil, err
 		}
-		resolvedL, err := LoadWith(cache, e.L, ancestors...)
+
+		// recursively load any more imports
+		expr, children, err = r.load(dynamicExpr, imports...)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		resolvedR, err := LoadWith(cache, e.R, ancestors...)
+	}
+	if err := r.acquire(); err != nil {
+		return nil, nil, err
+	}
+	defer r.release()
+	if mode == Code {
+		// ensure that expr typechecks in empty context
+		_, err = core.TypeOfWithLimits(r.ctx, expr, r.limits)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return Op{OpCode: e.OpCode, L: resolvedL, R: resolvedR}, nil
-	default:
-		// Const, NaturalLit, etc
-		return term.MaybeTransformSubexprs(e, func(t Term) (Term, error) {
-			return LoadWith(cache, t, ancestors...)
-		})
 	}
+
+	// evaluate expression
+	expr, err = core.NormalizeWithLimits(r.ctx, expr, r.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return expr, children, nil
+}
+
+// fetch fetches the source of here, from the Loader's Vendor if it
+// is vendored there under vendorKey.
+func (r *resolver) fetch(here Fetchable, vendorKey, origin string) (string, error) {
+	_, isRemote := here.(RemoteFile)
+	var content string
+	var vendored bool
+	var err error
+	if isRemote {
+		content, vendored, err = r.fetchVendored(vendorKey)
+	}
+	if !vendored && err == nil {
+		if with, ok := here.(FetchableWith); ok {
+			content, err = with.FetchWith(origin, contextFetcher{r.fetcher, r.ctx})
+		} else {
+			content, err = here.Fetch(origin)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if isRemote && r.onFetch != nil {
+		r.onFetch(vendorKey, content)
+	}
+	return content, nil
+}
+
+// concurrentCache returns cache, if it is known to be safe for
+// concurrent use, and otherwise cache wrapped in a lockedCache, so
+// that the imports of a resolution can share it.
+func concurrentCache(cache DhallCache) DhallCache {
+	switch cache := cache.(type) {
+	case LocalCache, NoCache, RemoteCache, *lockedCache:
+		return cache
+	case CacheChain:
+		safe := make(CacheChain, len(cache))
+		for i, c := range cache {
+			safe[i] = concurrentCache(c)
+		}
+		return safe
+	}
+	return &lockedCache{cache: cache}
+}
+
+// A lockedCache serializes the use of a DhallCache.
+type lockedCache struct {
+	mu    sync.Mutex
+	cache DhallCache
+}
+
+func (c *lockedCache) Fetch(hash []byte) Term {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Fetch(hash)
+}
+
+func (c *lockedCache) Save(hash []byte, t Term) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Save(hash, t)
+}
+
+func (c *lockedCache) FetchContext(ctx context.Context, hash []byte) Term {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fetchContext(ctx, c.cache, hash)
+}
+
+func (c *lockedCache) SaveContext(ctx context.Context, hash []byte, t Term) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	saveContext(ctx, c.cache, hash, t)
+}
+
+var headersType = core.ListOf{Type: core.RecordType{
+	"mapKey":   core.Text,
+	"mapValue": core.Text,
+}}
+
+// withUserHeaders returns remote with the headers to send when
+// fetching it: using, the resolved headers from its `using` clause,
+// if any, plus any user-supplied headers for its origin.
+func (r *resolver) withUserHeaders(remote RemoteFile, using Term) (RemoteFile, error) {
+	userHeaders, err := r.userHeadersFor(remote)
+	if err != nil {
+		return RemoteFile{}, err
+	}
+	return remote.WithHeaders(mergeHeaders(userHeaders, using)), nil
+}
+
+// loadHeaders resolves the headers expression of a `using` clause,
+// in the context of the import which contains it, and returns it in
+// normal form.
+func (r *resolver) loadHeaders(headers Term, ancestors ...Fetchable) (Term, []*Dependency, error) {
+	headers, deps, err := r.load(headers, ancestors...)
+	if err != nil {
+		return nil, nil, err
+	}
+	typ, err := core.TypeOfWithLimits(r.ctx, headers, r.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !core.AlphaEquivalent(typ, headersType) {
+		return nil, nil, errors.New("❰using❱ headers must have type List { mapKey : Text, mapValue : Text }")
+	}
+	headers, err = core.NormalizeWithLimits(r.ctx, headers, r.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, deps, nil
 }
@@ -1,12 +1,24 @@
 package imports_test
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
 
+	"github.com/wallyqs/dhall.go/core"
 	. "github.com/wallyqs/dhall.go/imports"
 	. "github.com/wallyqs/dhall.go/internal"
+	"github.com/wallyqs/dhall.go/parser"
 	. "github.com/wallyqs/dhall.go/term"
 
 	. "github.com/onsi/ginkgo"
@@ -84,6 +96,15 @@ var _ = Describe("Import resolution", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(Equal(PlainText("abcd")))
 		})
+		It("Resolves as Bytes", func() {
+			server.RouteToHandler("GET", "/foo.bin",
+				ghttp.RespondWith(http.StatusOK, "\x00\xff"),
+			)
+			actual, err := Load(NewRemoteImport(server.URL()+"/foo.bin", RawBytes))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(BytesLit{0x00, 0xff}))
+		})
 		It("Resolves as code", func() {
 			server.RouteToHandler("GET", "/foo.dhall",
 				ghttp.RespondWith(http.StatusOK, "3 : Natural"),
@@ -101,6 +122,158 @@ var _ = Describe("Import resolution", func() {
 
 			Expect(err).To(HaveOccurred())
 		})
+		It("Cancels a slow request when the context is done", func() {
+			server.RouteToHandler("GET", "/slow.dhall", func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := LoadContext(ctx, NewRemoteImport(server.URL()+"/slow.dhall", Code))
+
+			Expect(err).To(MatchError(ContainSubstring(context.DeadlineExceeded.Error())))
+		})
+		Describe("using headers", func() {
+			load := func(source string) (Term, error) {
+				parsed, err := parser.Parse("-", []byte(source))
+				Expect(err).ToNot(HaveOccurred())
+				return Load(parsed)
+			}
+			const headers = `[ { mapKey = "X-Token", mapValue = "secret" } ]`
+			BeforeEach(func() {
+				server.RouteToHandler("GET", "/echo.dhall",
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("Access-Control-Allow-Origin", "*")
+						io.WriteString(w, `"`+r.Header.Get("X-Token")+`"`)
+					},
+				)
+				server.RouteToHandler("GET", "/relative.dhall",
+					ghttp.RespondWith(http.StatusOK, "./echo.dhall"),
+				)
+			})
+			It("sends the headers", func() {
+				actual, err := load(server.URL() + "/echo.dhall using " + headers)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("secret")))
+			})
+			It("resolves imports in the headers expression", func() {
+				os.Setenv("HEADERS", headers)
+				actual, err := load(server.URL() + "/echo.dhall using env:HEADERS")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("secret")))
+			})
+			It("forwards the headers to relative imports", func() {
+				actual, err := load(server.URL() + "/relative.dhall using " + headers)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("secret")))
+			})
+			It("does not forward the headers to absolute imports", func() {
+				otherOrigin := ghttp.NewServer()
+				defer otherOrigin.Close()
+				otherOrigin.RouteToHandler("GET", "/absolute.dhall",
+					ghttp.RespondWith(http.StatusOK, server.URL()+"/echo.dhall"),
+				)
+				actual, err := load(otherOrigin.URL() + "/absolute.dhall using " + headers)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("")))
+			})
+			It("rejects headers of the wrong type", func() {
+				_, err := load(server.URL() + `/echo.dhall using [ { mapKey = "X-Token", mapValue = 1 } ]`)
+
+				Expect(err).To(HaveOccurred())
+			})
+			It("rejects headers with free variables", func() {
+				_, err := load(server.URL() + "/echo.dhall using x")
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Describe("user-supplied headers", func() {
+			var origin string
+			BeforeEach(func() {
+				origin = strings.TrimPrefix(server.URL(), "http://")
+				server.RouteToHandler("GET", "/echo.dhall",
+					func(w http.ResponseWriter, r *http.Request) {
+						io.WriteString(w, `"`+r.Header.Get("X-Token")+`"`)
+					},
+				)
+			})
+			AfterEach(func() {
+				os.Unsetenv("DHALL_HEADERS")
+			})
+			config := func(origin string) string {
+				return `[ { mapKey = "` + origin + `", mapValue = [ { mapKey = "X-Token", mapValue = "from-config" } ] } ]`
+			}
+			load := func(source string) (Term, error) {
+				parsed, err := parser.Parse("-", []byte(source))
+				Expect(err).ToNot(HaveOccurred())
+				return Load(parsed)
+			}
+			It("sends headers from DHALL_HEADERS to a matching origin", func() {
+				os.Setenv("DHALL_HEADERS", config(origin))
+				actual, err := load(server.URL() + "/echo.dhall")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("from-config")))
+			})
+			It("does not send headers to other origins", func() {
+				os.Setenv("DHALL_HEADERS", config("example.com:443"))
+				actual, err := load(server.URL() + "/echo.dhall")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("")))
+			})
+			It("prefers user-supplied headers to those from a using clause", func() {
+				os.Setenv("DHALL_HEADERS", config(origin))
+				actual, err := load(server.URL() + `/echo.dhall using [ { mapKey = "x-token", mapValue = "inline" } ]`)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("from-config")))
+			})
+			It("rejects a configuration of the wrong type", func() {
+				os.Setenv("DHALL_HEADERS", `[ { mapKey = "X-Token", mapValue = "oops" } ]`)
+				_, err := load(server.URL() + "/echo.dhall")
+
+				Expect(err).To(HaveOccurred())
+			})
+			It("reads headers.dhall from the config directory", func() {
+				if runtime.GOOS == "windows" {
+					Skip("XDG_CONFIG_HOME is not honoured on Windows")
+				}
+				configHome, err := ioutil.TempDir("", "dhall-config")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(configHome)
+				Expect(os.Mkdir(filepath.Join(configHome, "dhall"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(configHome, "dhall", "headers.dhall"),
+					[]byte(config(origin)), 0644)).To(Succeed())
+				defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+				os.Setenv("XDG_CONFIG_HOME", configHome)
+
+				actual, err := load(server.URL() + "/echo.dhall")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("from-config")))
+			})
+			It("reads headers.dhall with the Loader's environment and files", func() {
+				if runtime.GOOS == "windows" {
+					Skip("XDG_CONFIG_HOME is not honoured on Windows")
+				}
+				defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+				os.Setenv("XDG_CONFIG_HOME", "/nonexistent")
+				loader := NewLoader(WithCache(NoCache{}),
+					WithEnv(map[string]string{"XDG_CONFIG_HOME": "/config"}),
+					WithFS(fstest.MapFS{"config/dhall/headers.dhall": {Data: []byte(config(origin))}}))
+				parsed, err := parser.Parse("-", []byte(server.URL()+"/echo.dhall"))
+				Expect(err).ToNot(HaveOccurred())
+				actual, err := loader.Load(parsed)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(PlainText("from-config")))
+			})
+		})
 		Describe("CORS checks", func() {
 			BeforeEach(func() {
 				server.RouteToHandler("GET", "/no-cors.dhall",
@@ -144,6 +317,19 @@ var _ = Describe("Import resolution", func() {
 					_, err := Load(NewRemoteImport(otherOrigin.URL()+"/other-origin.dhall", Code))
 					Expect(err).To(HaveOccurred())
 				})
+				It("refuses if CORS fails, even if the import was allowed from elsewhere", func() {
+					otherOrigin := ghttp.NewServer()
+					otherOrigin.RouteToHandler("GET", "/other-origin.dhall",
+						ghttp.RespondWith(http.StatusOK, server.URL()+"/no-cors.dhall"),
+					)
+					expr := NonEmptyList{
+						NewRemoteImport(server.URL()+"/no-cors.dhall", Code),
+						NewRemoteImport(otherOrigin.URL()+"/other-origin.dhall", Code),
+					}
+
+					_, err := Load(expr)
+					Expect(err).To(HaveOccurred())
+				})
 				It("allows if Access-Control-Allow-Origin is '*'", func() {
 					otherOrigin := ghttp.NewServer()
 					otherOrigin.RouteToHandler("GET", "/other-origin.dhall",
@@ -184,6 +370,12 @@ var _ = Describe("Import resolution", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(Equal(PlainText("here is some text\n")))
 		})
+		It("Resolves as Bytes", func() {
+			actual, err := Load(NewLocalImport("./testdata/binary.bin", RawBytes))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(BytesLit{0x00, 0xff, 0x10}))
+		})
 		It("Resolves as code", func() {
 			actual, err := Load(NewLocalImport("./testdata/natural.dhall", Code))
 
@@ -213,3 +405,349 @@ var _ = Describe("Import resolution", func() {
 		})
 	})
 })
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// sourceFetchable is a Fetchable which only has the methods of
+// Fetchable, and fetches to itself.
+type sourceFetchable string
+
+func (s sourceFetchable) Origin() string                         { return NullOrigin }
+func (s sourceFetchable) Fetch(string) (string, error)           { return string(s), nil }
+func (s sourceFetchable) ChainOnto(Fetchable) (Fetchable, error) { return s, nil }
+func (s sourceFetchable) String() string                         { return "source:" + string(s) }
+func (s sourceFetchable) AsLocation() Term                       { return Missing{}.AsLocation() }
+
+var _ = Describe("Loader", func() {
+	It("fetches local files from an fs.FS", func() {
+		fsys := fstest.MapFS{
+			"config/main.dhall":    {Data: []byte("./sub/two.dhall + ../one.dhall")},
+			"config/sub/two.dhall": {Data: []byte("2")},
+			"one.dhall":            {Data: []byte("1")},
+		}
+		loader := NewLoader(WithCache(NoCache{}), WithFS(fsys))
+		actual, err := loader.Load(NewLocalImport("config/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NaturalLit(3)))
+	})
+	It("locates type errors in imported files", func() {
+		fsys := fstest.MapFS{
+			"main.dhall": {Data: []byte("./bad.dhall")},
+			"bad.dhall":  {Data: []byte("{ a = 1 }\n.b")},
+		}
+		loader := NewLoader(WithCache(NoCache{}), WithFS(fsys))
+		_, err := loader.Load(NewLocalImport("main.dhall", Code))
+
+		Expect(err).To(MatchError(HavePrefix("./bad.dhall:1:1: Missing record field")))
+	})
+	It("fetches Fetchables which aren't FetchableWiths with Fetch()", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithFS(fstest.MapFS{}))
+		actual, err := loader.Load(NewImport(sourceFetchable("1 + 1"), Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NaturalLit(2)))
+	})
+	It("refuses to fetch paths outside an fs.FS", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithFS(fstest.MapFS{}))
+		_, err := loader.Load(NewLocalImport("../outside.dhall", Code))
+
+		Expect(err).To(HaveOccurred())
+	})
+	It("stops if the context is already done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		loader := NewLoader(WithCache(NoCache{}), WithEnv(map[string]string{"ONE": "1"}))
+		_, err := loader.LoadContext(ctx, NewEnvVarImport("ONE", Code))
+
+		Expect(err).To(MatchError(context.Canceled))
+	})
+	It("stops evaluating an import when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		loader := NewLoader(WithCache(NoCache{}), WithEnv(map[string]string{
+			"SLOW": "Natural/fold 100000000000 Integer Integer/negate +1",
+		}))
+		_, err := loader.LoadContext(ctx, NewEnvVarImport("SLOW", Code))
+
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+	It("evaluates imports subject to limits", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithLimits(core.Limits{MaxListLength: 2}), WithEnv(map[string]string{
+			"LIST": "[1, 2] # [3]",
+		}))
+		_, err := loader.Load(NewEnvVarImport("LIST", Code))
+
+		Expect(err).To(Equal(&core.LimitError{Limit: core.ListLengthLimit, Max: 2}))
+	})
+	It("fetches local files with a custom function", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithReadFile(func(name string) ([]byte, error) {
+			return []byte(`"contents of ` + name + `"`), nil
+		}))
+		actual, err := loader.Load(NewLocalImport("/some/file", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(PlainText("contents of /some/file")))
+	})
+	It("fetches environment variables from a map", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithEnv(map[string]string{
+			"ONE": "env:TWO + 1",
+			"TWO": "2",
+		}))
+		actual, err := loader.Load(NewEnvVarImport("ONE", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NaturalLit(3)))
+
+		_, err = loader.Load(NewEnvVarImport("PATH", Code))
+		Expect(err).To(HaveOccurred())
+	})
+	It("fetches remote files with a custom RoundTripper", func() {
+		loader := NewLoader(WithCache(NoCache{}), WithRoundTripper(roundTripperFunc(
+			func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`"fetched ` + req.URL.Path + `"`)),
+					Request:    req,
+				}, nil
+			})))
+		actual, err := loader.Load(NewRemoteImport("https://example.com/foo.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(PlainText("fetched /foo.dhall")))
+	})
+})
+
+// slowFiles returns a readFile function which fetches files from
+// files after delay[name], and reports the most files it was ever
+// fetching at once.
+func slowFiles(files map[string]string, delay map[string]time.Duration) (readFile func(string) ([]byte, error), maxInFlight func() int) {
+	var mu sync.Mutex
+	inFlight, max := 0, 0
+	readFile = func(name string) ([]byte, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > max {
+			max = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(delay[name])
+		content, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+	return readFile, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return max
+	}
+}
+
+// slowCache is a DhallCache which caches nothing, slowly, and
+// records how many of its calls were in flight at once.
+type slowCache struct {
+	mu            sync.Mutex
+	inFlight, max int
+}
+
+func (c *slowCache) call() {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.max {
+		c.max = c.inFlight
+	}
+	c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+}
+
+func (c *slowCache) Fetch([]byte) Term { c.call(); return nil }
+func (c *slowCache) Save([]byte, Term) { c.call() }
+
+var _ = Describe("Concurrent resolution", func() {
+	files := map[string]string{
+		"/main.dhall": "[ ./a.dhall, ./b.dhall, ./c.dhall, ./d.dhall ]",
+		"/a.dhall":    "1",
+		"/b.dhall":    "2",
+		"/c.dhall":    "3",
+		"/d.dhall":    "4",
+	}
+	delay := map[string]time.Duration{
+		"/a.dhall": 20 * time.Millisecond,
+		"/b.dhall": 20 * time.Millisecond,
+		"/c.dhall": 20 * time.Millisecond,
+		"/d.dhall": 20 * time.Millisecond,
+	}
+	expected := NonEmptyList{NaturalLit(1), NaturalLit(2), NaturalLit(3), NaturalLit(4)}
+
+	It("fetches independent imports at once, up to the limit", func() {
+		readFile, maxInFlight := slowFiles(files, delay)
+		loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(2))
+		actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+		Expect(maxInFlight()).To(Equal(2))
+	})
+	It("fetches imports one at a time with a concurrency of 1", func() {
+		readFile, maxInFlight := slowFiles(files, delay)
+		loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(1))
+		actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+		Expect(maxInFlight()).To(Equal(1))
+	})
+	It("fetches imports one at a time by default", func() {
+		readFile, maxInFlight := slowFiles(files, delay)
+		loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile))
+		actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+		Expect(maxInFlight()).To(Equal(1))
+	})
+	It("reports the first failing import, whichever fails first", func() {
+		files := map[string]string{
+			"/main.dhall":  "[ ./a.dhall, ./missing.dhall, ./b.dhall + True ]",
+			"/a.dhall":     "./cycle.dhall",
+			"/cycle.dhall": "./a.dhall",
+			"/b.dhall":     "2",
+		}
+		delay := map[string]time.Duration{"/cycle.dhall": 50 * time.Millisecond}
+		readFile, _ := slowFiles(files, delay)
+		_, sequentialErr := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(1)).
+			Load(NewLocalImport("/main.dhall", Code))
+		_, concurrentErr := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(4)).
+			Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(sequentialErr).To(MatchError(ContainSubstring("import cycle")))
+		Expect(concurrentErr).To(Equal(sequentialErr))
+	})
+	It("serializes its calls to caches of other types", func() {
+		hash := " sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"
+		files := map[string]string{
+			"/main.dhall": "[ ./a.dhall" + hash + ", ./b.dhall" + hash + ", ./c.dhall" + hash + " ]",
+			"/a.dhall":    "1",
+			"/b.dhall":    "1",
+			"/c.dhall":    "1",
+		}
+		readFile, _ := slowFiles(files, nil)
+		cache := &slowCache{}
+		loader := NewLoader(WithCache(cache), WithReadFile(readFile), WithConcurrency(4))
+		_, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.max).To(Equal(1))
+	})
+	It("tries the second of an import alternative only if the first fails", func() {
+		files := map[string]string{
+			"/main.dhall": "[ ./missing.dhall ? ./a.dhall, ./b.dhall ? ./c.dhall ]",
+			"/a.dhall":    "1",
+			"/b.dhall":    "2",
+		}
+		readFile, _ := slowFiles(files, nil)
+		loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(4))
+		actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NonEmptyList{NaturalLit(1), NaturalLit(2)}))
+	})
+})
+
+// countingFiles returns a readFile function which fetches files from
+// files, and reports how many times it fetched each.  Each fetch of
+// "/counter.dhall" returns how many times it was fetched.
+func countingFiles(files map[string]string) (readFile func(string) ([]byte, error), fetches func(string) int) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	readFile = func(name string) ([]byte, error) {
+		mu.Lock()
+		counts[name]++
+		count := counts[name]
+		mu.Unlock()
+		if name == "/counter.dhall" {
+			return []byte(strconv.Itoa(count)), nil
+		}
+		time.Sleep(10 * time.Millisecond)
+		content, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+	return readFile, func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[name]
+	}
+}
+
+var _ = Describe("Memoization", func() {
+	for _, concurrency := range []int{1, 4} {
+		concurrency := concurrency
+		Context(fmt.Sprintf("with a concurrency of %d", concurrency), func() {
+			It("fetches each import once per Load", func() {
+				readFile, fetches := countingFiles(map[string]string{
+					"/main.dhall":  "[ ./a.dhall, ./sub/b.dhall, ./a.dhall ]",
+					"/a.dhall":     "1",
+					"/sub/b.dhall": "../a.dhall + 1",
+				})
+				loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(concurrency))
+				actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(NonEmptyList{NaturalLit(1), NaturalLit(2), NaturalLit(1)}))
+				Expect(fetches("/a.dhall")).To(Equal(1))
+
+				_, err = loader.Load(NewLocalImport("/main.dhall", Code))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fetches("/a.dhall")).To(Equal(2))
+			})
+			It("resolves the same import to the same expression", func() {
+				readFile, _ := countingFiles(map[string]string{
+					"/main.dhall": "[ ./counter.dhall, ./counter.dhall ]",
+				})
+				loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(concurrency))
+				actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(NonEmptyList{NaturalLit(1), NaturalLit(1)}))
+			})
+			It("resolves each import mode separately", func() {
+				readFile, fetches := countingFiles(map[string]string{
+					"/main.dhall": "{ text = ./a.dhall as Text, code = ./a.dhall }",
+					"/a.dhall":    "1",
+				})
+				loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(concurrency))
+				actual, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(RecordLit{"text": PlainText("1"), "code": NaturalLit(1)}))
+				Expect(fetches("/a.dhall")).To(Equal(2))
+			})
+			It("rejects import cycles between imports resolved at once", func() {
+				readFile, _ := countingFiles(map[string]string{
+					"/main.dhall": "[ ./x.dhall, ./y.dhall ]",
+					"/x.dhall":    "./y.dhall",
+					"/y.dhall":    "./x.dhall",
+				})
+				loader := NewLoader(WithCache(NoCache{}), WithReadFile(readFile), WithConcurrency(concurrency))
+				_, err := loader.Load(NewLocalImport("/main.dhall", Code))
+
+				Expect(err).To(MatchError("Detected import cycle in /x.dhall"))
+			})
+		})
+	}
+})
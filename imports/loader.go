@@ -0,0 +1,195 @@
+package imports
+
+import (
+	"context"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/wallyqs/dhall.go/core"
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// A Loader resolves imports.  It determines which cache to use, and
+// how local files, environment variables and remote files are
+// fetched.  By default, a Loader uses the standard cache, and fetches
+// from the local filesystem, the process environment and the network,
+// just like Load() does.
+//
+// A Loader resolves imports one at a time, in the order they appear,
+// unless asked to resolve independent imports concurrently with
+// WithConcurrency.
+//
+// A Loader holds no state between calls to Load(), and is safe for
+// concurrent use if its cache and fetchers are.
+type Loader struct {
+	cache       DhallCache
+	fetcher     *fetcher
+	limits      core.Limits
+	concurrency int
+	policy      Policy
+	// set if local files are read with WithReadFile, rather than
+	// from the local filesystem
+	ownReadFile bool
+
+	vendor       *Vendor
+	strictVendor bool
+}
+
+// A LoaderOption configures a Loader.
+type LoaderOption func(*Loader)
+
+// NewLoader creates a Loader with the given options.
+func NewLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{
+		fetcher: &fetcher{
+			readFile:  ioutil.ReadFile,
+			lookupEnv: os.LookupEnv,
+			client:    http.DefaultClient,
+		},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if len(l.policy.AllowedHosts) > 0 {
+		l.fetcher.client = l.policy.guardRedirects(l.fetcher.client)
+	}
+	if l.cache == nil {
+		cache, err := StandardCache()
+		if err != nil {
+			cache = NoCache{}
+		}
+		l.cache = cache
+	}
+	return l
+}
+
+// WithCache makes the Loader use cache for saving and fetching
+// imports.
+func WithCache(cache DhallCache) LoaderOption {
+	return func(l *Loader) { l.cache = cache }
+}
+
+// WithReadFile makes the Loader fetch local files with readFile,
+// which is passed the path of the file as it appears in the Dhall
+// source, after import chaining.
+func WithReadFile(readFile func(name string) ([]byte, error)) LoaderOption {
+	return func(l *Loader) { l.fetcher.readFile, l.ownReadFile = readFile, true }
+}
+
+// WithFS makes the Loader fetch local files from fsys.  Absolute
+// paths are resolved from the root of fsys, and here-relative paths
+// from the root too, unless they are chained onto another local file.
+// Home-relative paths, and paths which escape the root of fsys, can't
+// be fetched.
+func WithFS(fsys fs.FS) LoaderOption {
+	return WithReadFile(func(name string) ([]byte, error) {
+		fsName := path.Clean(name)
+		if path.IsAbs(fsName) {
+			fsName = fsName[1:]
+			if fsName == "" {
+				fsName = "."
+			}
+		}
+		if !fs.ValidPath(fsName) || LocalFile(name).IsRelativeToHome() {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+		}
+		return fs.ReadFile(fsys, fsName)
+	})
+}
+
+// WithLookupEnv makes the Loader fetch environment variables with
+// lookupEnv, which has the same contract as os.LookupEnv.
+func WithLookupEnv(lookupEnv func(name string) (string, bool)) LoaderOption {
+	return func(l *Loader) { l.fetcher.lookupEnv = lookupEnv }
+}
+
+// WithEnv makes the Loader fetch environment variables from env
+// instead of the process environment.
+func WithEnv(env map[string]string) LoaderOption {
+	return WithLookupEnv(func(name string) (string, bool) {
+		val, ok := env[name]
+		return val, ok
+	})
+}
+
+// WithRoundTripper makes the Loader fetch remote files with an
+// http.Client which uses rt.
+func WithRoundTripper(rt http.RoundTripper) LoaderOption {
+	return WithHTTPClient(&http.Client{Transport: rt})
+}
+
+// WithHTTPClient makes the Loader fetch remote files with client.
+func WithHTTPClient(client *http.Client) LoaderOption {
+	return func(l *Loader) { l.fetcher.client = client }
+}
+
+// WithLimits makes the Loader typecheck and evaluate imported
+// expressions subject to limits.
+func WithLimits(limits core.Limits) LoaderOption {
+	return func(l *Loader) { l.limits = limits }
+}
+
+// Limits returns the limits which the Loader typechecks and
+// evaluates imported expressions subject to; see WithLimits.
+func (l *Loader) Limits() core.Limits { return l.limits }
+
+// WithPolicy makes the Loader refuse the imports which policy
+// forbids.
+func WithPolicy(policy Policy) LoaderOption {
+	return func(l *Loader) { l.policy = policy }
+}
+
+// WithConcurrency makes the Loader fetch and evaluate up to n imports
+// at once.  Whatever n is, the Loader's results and errors are the
+// same as if it resolved imports one at a time, in the order they
+// appear; but with n greater than 1, its fetchers are called
+// concurrently, and so they must be safe for concurrent use, as is
+// its cache, if it is one of this package's.  With n of 1 or less,
+// the Loader resolves imports one at a time.
+func WithConcurrency(n int) LoaderOption {
+	if n < 1 {
+		n = 1
+	}
+	return func(l *Loader) { l.concurrency = n }
+}
+
+// Load takes a Term and resolves all imports.  ancestors, if given,
+// are the imports which e was loaded from; relative imports in e are
+// chained onto the last of them.
+func (l *Loader) Load(e Term, ancestors ...Fetchable) (Term, error) {
+	return l.LoadContext(context.Background(), e, ancestors...)
+}
+
+// LoadContext is like Load, but stops resolving imports and returns
+// ctx's error if ctx is done before resolution finishes.  ctx is also
+// used for any HTTP requests made to fetch remote imports.
+func (l *Loader) LoadContext(ctx context.Context, e Term, ancestors ...Fetchable) (Term, error) {
+	expr, _, err := newResolver(l, ctx).load(e, ancestors...)
+	return expr, err
+}
+
+// fetcher is the Fetcher which a Loader passes to FetchWith().
+type fetcher struct {
+	readFile  func(name string) ([]byte, error)
+	lookupEnv func(name string) (string, bool)
+	client    *http.Client
+}
+
+var _ Fetcher = (*fetcher)(nil)
+
+func (f *fetcher) ReadFile(name string) ([]byte, error)         { return f.readFile(name) }
+func (f *fetcher) LookupEnv(name string) (string, bool)         { return f.lookupEnv(name) }
+func (f *fetcher) Do(req *http.Request) (*http.Response, error) { return f.client.Do(req) }
+
+// contextFetcher is a fetcher which makes HTTP requests with ctx.
+type contextFetcher struct {
+	*fetcher
+	ctx context.Context
+}
+
+func (f contextFetcher) Do(req *http.Request) (*http.Response, error) {
+	return f.fetcher.Do(req.WithContext(f.ctx))
+}
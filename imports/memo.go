@@ -0,0 +1,135 @@
+package imports
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// A memo records the imports resolved during a single Load, so that
+// each is fetched and evaluated only once, and resolves to the same
+// expression wherever it appears, as the standard requires.
+type memo struct {
+	mu      sync.Mutex
+	entries map[memoKey]*memoEntry
+	// waits[k] holds the imports which the resolution of k has
+	// waited for, or resolved, itself or in the imports it resolves
+	waits map[memoKey]map[memoKey]bool
+}
+
+// A memoKey identifies an import within a single Load: its location,
+// chained onto the imports it was loaded from and with the headers it
+// is fetched with, and how it is imported.  A remote import's key
+// also holds the origin it is imported from, since whether it may be
+// fetched depends on that origin's CORS check.
+type memoKey struct {
+	location string
+	origin   string
+	mode     ImportMode
+}
+
+// A memoEntry is the resolution of an import, which is finished once
+// done is closed.
+type memoEntry struct {
+	done     chan struct{}
+	expr     Term
+	children []*Dependency
+	err      error
+}
+
+func newMemo() *memo {
+	return &memo{
+		entries: make(map[memoKey]*memoEntry),
+		waits:   make(map[memoKey]map[memoKey]bool),
+	}
+}
+
+// keyOf returns the memoKey of here, imported from origin.
+func keyOf(here Fetchable, origin string, mode ImportMode) memoKey {
+	remote, ok := here.(RemoteFile)
+	if !ok {
+		return memoKey{location: here.String(), mode: mode}
+	}
+	location := here.String()
+	if remote.Headers() != nil {
+		location += " using " + fmt.Sprint(remote.Headers())
+	}
+	return memoKey{location: location, origin: origin, mode: mode}
+}
+
+// claim looks up the resolution of key, which is imported from within
+// the import within, if any.  If key has been resolved successfully,
+// claim returns its entry.  If key has not been resolved yet, claim
+// returns a new entry, and owned is true: the caller must resolve key
+// and pass the result to finish.  If key is being resolved elsewhere,
+// claim waits for it to finish, unless that resolution waits in turn
+// for within, which would be a deadlock.
+//
+// claim returns a nil entry if the caller must resolve key without
+// the memo: because waiting for key would deadlock, or because the
+// resolution it waited for failed.  A failure isn't recorded, since
+// its error may depend on where key was imported from.
+func (m *memo) claim(key memoKey, within *memoKey) (entry *memoEntry, owned bool) {
+	m.mu.Lock()
+	deadlock := false
+	if within != nil {
+		deadlock = m.reaches(key, *within)
+		if m.waits[*within] == nil {
+			m.waits[*within] = make(map[memoKey]bool)
+		}
+		m.waits[*within][key] = true
+	}
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoEntry{done: make(chan struct{})}
+		m.entries[key] = entry
+		m.mu.Unlock()
+		return entry, true
+	}
+	m.mu.Unlock()
+	select {
+	case <-entry.done:
+	default:
+		if deadlock {
+			return nil, false
+		}
+		<-entry.done
+	}
+	if entry.err != nil {
+		return nil, false
+	}
+	return entry, false
+}
+
+// reaches reports whether the resolution of from waits, directly or
+// not, for to.
+func (m *memo) reaches(from, to memoKey) bool {
+	seen := map[memoKey]bool{from: true}
+	queue := []memoKey{from}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		if k == to {
+			return true
+		}
+		for next := range m.waits[k] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// finish records the resolution of key, which the caller claimed.
+func (m *memo) finish(key memoKey, entry *memoEntry, expr Term, children []*Dependency, err error) {
+	if err != nil {
+		m.mu.Lock()
+		delete(m.entries, key)
+		m.mu.Unlock()
+	}
+	entry.expr, entry.children, entry.err = expr, children, err
+	close(entry.done)
+}
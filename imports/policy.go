@@ -0,0 +1,194 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// A Policy restricts what a Loader may import, for loading Dhall from
+// untrusted sources.  The Loader checks each import against its
+// Policy, after chaining it, before fetching it or looking it up in
+// the cache, and fails with a *PolicyError if the Policy forbids it.
+// `as Location` imports and `missing` fetch nothing, and are always
+// allowed.  The zero Policy allows everything.
+type Policy struct {
+	// NoRemote forbids remote imports.
+	NoRemote bool
+	// AllowedHosts, if not empty, are the only hosts which remote
+	// imports may be fetched from, even by following a redirect.
+	// An entry of the form "host:port" allows only that port; an
+	// entry of the form "host" allows any.  Hosts are compared
+	// case-insensitively.
+	AllowedHosts []string
+	// NoEnv forbids environment variable imports.
+	NoEnv bool
+	// NoLocal forbids local imports.
+	NoLocal bool
+	// LocalRoot, if set, is the directory which local imports must
+	// be within.  Relative paths are taken relative to the current
+	// directory, and home-relative imports are forbidden.  Paths are
+	// compared after resolving any `..` components and symbolic
+	// links, so that a link within the root can't lead out of it.
+	//
+	// A Loader which reads local files with WithReadFile or WithFS
+	// compares paths lexically, without resolving symbolic links,
+	// since it doesn't read the local filesystem; it is up to its
+	// readFile not to follow links out of the root.
+	LocalRoot string
+}
+
+// A PolicyError is returned when a Loader's Policy forbids an import.
+type PolicyError struct {
+	// Chain is the chain of imports which led to the forbidden one:
+	// the imports it was loaded from, then the import itself,
+	// chained onto them.
+	Chain []Fetchable
+	// Reason says which rule of the Policy forbids the import.
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	if len(e.Chain) == 0 {
+		return "Forbidden import: " + e.Reason
+	}
+	chain := make([]string, len(e.Chain))
+	for i, f := range e.Chain {
+		chain[i] = f.String()
+	}
+	return fmt.Sprintf("Forbidden import of %s: %s (import chain: %s)",
+		e.Chain[len(e.Chain)-1], e.Reason, strings.Join(chain, " -> "))
+}
+
+// check returns a *PolicyError if p forbids importing here, which is
+// imported from ancestors.  If evalSymlinks is set, symbolic links in
+// local paths are resolved in the local filesystem.
+func (p Policy) check(here Fetchable, ancestors []Fetchable, evalSymlinks bool) error {
+	var reason string
+	switch here := here.(type) {
+	case RemoteFile:
+		u, err := url.Parse(here.String())
+		if err != nil {
+			return err
+		}
+		reason = p.checkRemote(u)
+	case EnvVar:
+		if p.NoEnv {
+			reason = "environment variable imports are not allowed"
+		}
+	case LocalFile:
+		if p.NoLocal {
+			reason = "local imports are not allowed"
+		} else if p.LocalRoot != "" && !p.withinRoot(here, evalSymlinks) {
+			reason = "local imports must be within " + p.LocalRoot
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	return &PolicyError{Chain: append(ancestors[:len(ancestors):len(ancestors)], here), Reason: reason}
+}
+
+// checkRemote returns why p forbids fetching u, or "" if it doesn't.
+func (p Policy) checkRemote(u *url.URL) string {
+	if p.NoRemote {
+		return "remote imports are not allowed"
+	}
+	if len(p.AllowedHosts) == 0 {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	for _, allowed := range p.AllowedHosts {
+		// hostnames are case-insensitive
+		if strings.EqualFold(allowed, u.Hostname()) || strings.EqualFold(allowed, u.Hostname()+":"+port) {
+			return ""
+		}
+	}
+	return "host " + u.Host + " is not allowed"
+}
+
+func (p Policy) withinRoot(file LocalFile, evalSymlinks bool) bool {
+	if file.IsRelativeToHome() {
+		return false
+	}
+	name, root := string(file), p.LocalRoot
+	if !path.IsAbs(name) || !path.IsAbs(root) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return false
+		}
+		if !path.IsAbs(name) {
+			name = path.Join(cwd, name)
+		}
+		if !path.IsAbs(root) {
+			root = path.Join(cwd, root)
+		}
+	}
+	name, root = path.Clean(name), path.Clean(root)
+	if evalSymlinks {
+		var err error
+		if name, err = resolveSymlinks(name); err != nil {
+			return false
+		}
+		if root, err = resolveSymlinks(root); err != nil {
+			return false
+		}
+	}
+	return name == root || root == "/" || strings.HasPrefix(name, root+"/")
+}
+
+// resolveSymlinks returns the absolute path name with any symbolic
+// links in it resolved.  Links are resolved as far as name exists;
+// the components of name which don't exist are kept as they are.
+func resolveSymlinks(name string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(name)
+	if err == nil {
+		return filepath.ToSlash(resolved), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	dir, base := path.Split(name)
+	if dir == name || dir == "" {
+		return name, nil
+	}
+	dir, err = resolveSymlinks(path.Clean(dir))
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, base), nil
+}
+
+// guardRedirects returns a copy of client which refuses to follow
+// redirects to hosts which p doesn't allow.  The *PolicyError it
+// fails with has no Chain; the resolver fills it in.
+func (p Policy) guardRedirects(client *http.Client) *http.Client {
+	guarded := *client
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if reason := p.checkRemote(req.URL); reason != "" {
+			return &PolicyError{Reason: "redirect to " + req.URL.String() + ": " + reason}
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+		// the default policy of http.Client
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &guarded
+}
@@ -0,0 +1,225 @@
+package imports_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	. "github.com/wallyqs/dhall.go/internal"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+// oneCache is a DhallCache which has 1 cached under every hash.
+type oneCache struct{}
+
+func (oneCache) Fetch([]byte) Term { return NaturalLit(1) }
+func (oneCache) Save([]byte, Term) {}
+
+func remoteFile(s string) RemoteFile {
+	u, err := url.Parse(s)
+	Expect(err).ToNot(HaveOccurred())
+	return NewRemoteFile(u)
+}
+
+var _ = Describe("Policy", func() {
+	fsys := fstest.MapFS{
+		"srv/config/main.dhall":     {Data: []byte("./sub/ok.dhall")},
+		"srv/config/sub/ok.dhall":   {Data: []byte("1")},
+		"srv/config/escape.dhall":   {Data: []byte("./sub/../../secret.dhall")},
+		"srv/config/remote.dhall":   {Data: []byte("https://example.com/one.dhall")},
+		"srv/config/env.dhall":      {Data: []byte("env:SECRET")},
+		"srv/config/location.dhall": {Data: []byte("env:SECRET as Location")},
+		"srv/config/home.dhall":     {Data: []byte("~/secret.dhall")},
+		"srv/config/frozen.dhall": {Data: []byte(
+			"https://example.com/one.dhall sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15")},
+		"srv/secret.dhall": {Data: []byte("2")},
+	}
+	load := func(policy Policy, file string, opts ...LoaderOption) (Term, error) {
+		opts = append([]LoaderOption{WithCache(NoCache{}), WithFS(fsys), WithEnv(map[string]string{"SECRET": "3"}), WithPolicy(policy)}, opts...)
+		return NewLoader(opts...).Load(NewLocalImport(file, Code))
+	}
+
+	It("allows everything by default", func() {
+		actual, err := load(Policy{}, "/srv/config/escape.dhall")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NaturalLit(2)))
+	})
+	It("forbids remote imports", func() {
+		_, err := load(Policy{NoRemote: true}, "/srv/config/remote.dhall")
+
+		Expect(err).To(Equal(&PolicyError{
+			Chain: []Fetchable{
+				LocalFile("/srv/config/remote.dhall"),
+				remoteFile("https://example.com/one.dhall"),
+			},
+			Reason: "remote imports are not allowed",
+		}))
+		Expect(err).To(MatchError("Forbidden import of https://example.com/one.dhall: remote imports are not allowed " +
+			"(import chain: /srv/config/remote.dhall -> https://example.com/one.dhall)"))
+	})
+	It("forbids imports even when they are cached", func() {
+		_, err := load(Policy{NoRemote: true}, "/srv/config/frozen.dhall", WithCache(oneCache{}))
+
+		Expect(err).To(BeAssignableToTypeOf(&PolicyError{}))
+	})
+	It("forbids environment variable imports", func() {
+		_, err := load(Policy{NoEnv: true}, "/srv/config/env.dhall")
+
+		Expect(err).To(Equal(&PolicyError{
+			Chain:  []Fetchable{LocalFile("/srv/config/env.dhall"), EnvVar("SECRET")},
+			Reason: "environment variable imports are not allowed",
+		}))
+	})
+	It("allows `as Location` imports, which fetch nothing", func() {
+		_, err := load(Policy{NoEnv: true}, "/srv/config/location.dhall")
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("forbids local imports", func() {
+		_, err := load(Policy{NoLocal: true}, "/srv/config/main.dhall")
+
+		Expect(err).To(Equal(&PolicyError{
+			Chain:  []Fetchable{LocalFile("/srv/config/main.dhall")},
+			Reason: "local imports are not allowed",
+		}))
+	})
+	Describe("LocalRoot", func() {
+		policy := Policy{LocalRoot: "/srv/config"}
+
+		It("allows local imports within the root", func() {
+			actual, err := load(policy, "/srv/config/main.dhall")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(1)))
+		})
+		It("forbids local imports which escape the root", func() {
+			_, err := load(policy, "/srv/config/escape.dhall")
+
+			Expect(err).To(Equal(&PolicyError{
+				Chain:  []Fetchable{LocalFile("/srv/config/escape.dhall"), LocalFile("/srv/secret.dhall")},
+				Reason: "local imports must be within /srv/config",
+			}))
+		})
+		It("forbids home-relative imports", func() {
+			_, err := load(policy, "/srv/config/home.dhall")
+
+			Expect(err).To(BeAssignableToTypeOf(&PolicyError{}))
+		})
+		It("forbids siblings with the root as a prefix", func() {
+			_, err := load(Policy{LocalRoot: "/srv/conf"}, "/srv/config/main.dhall")
+
+			Expect(err).To(BeAssignableToTypeOf(&PolicyError{}))
+		})
+		Context("in the local filesystem", func() {
+			var dir string
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "dhall-policy")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.Mkdir(filepath.Join(dir, "root"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(dir, "secret.dhall"), []byte("2"), 0644)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(dir, "root", "ok.dhall"), []byte("1"), 0644)).To(Succeed())
+				Expect(os.Symlink(filepath.Join(dir, "secret.dhall"), filepath.Join(dir, "root", "link.dhall"))).To(Succeed())
+				Expect(os.Symlink(dir, filepath.Join(dir, "root", "up"))).To(Succeed())
+				Expect(os.Symlink("ok.dhall", filepath.Join(dir, "root", "inside.dhall"))).To(Succeed())
+			})
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+			loadFile := func(file string) (Term, error) {
+				loader := NewLoader(WithCache(NoCache{}), WithPolicy(Policy{LocalRoot: filepath.Join(dir, "root")}))
+				return loader.Load(NewLocalImport(filepath.Join(dir, "root", file), Code))
+			}
+
+			It("allows symbolic links within the root", func() {
+				actual, err := loadFile("inside.dhall")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actual).To(Equal(NaturalLit(1)))
+			})
+			It("forbids symbolic links to files outside the root", func() {
+				_, err := loadFile("link.dhall")
+
+				Expect(err).To(BeAssignableToTypeOf(&PolicyError{}))
+			})
+			It("forbids paths through symbolic links to directories outside the root", func() {
+				_, err := loadFile("up/secret.dhall")
+
+				Expect(err).To(BeAssignableToTypeOf(&PolicyError{}))
+			})
+		})
+	})
+	Describe("AllowedHosts", func() {
+		var server, other *ghttp.Server
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			other = ghttp.NewServer()
+			server.RouteToHandler("GET", "/one.dhall", ghttp.RespondWith(http.StatusOK, "1"))
+			otherURL, err := url.Parse(other.URL())
+			Expect(err).ToNot(HaveOccurred())
+			server.RouteToHandler("GET", "/redirect.dhall", ghttp.RespondWith(http.StatusFound, nil,
+				http.Header{"Location": {"http://localhost:" + otherURL.Port() + "/one.dhall"}}))
+		})
+		AfterEach(func() {
+			server.Close()
+			other.Close()
+		})
+		loadRemote := func(policy Policy, s string) (Term, error) {
+			loader := NewLoader(WithCache(NoCache{}), WithPolicy(policy))
+			expr, err := parser.Parse("-", []byte(s))
+			Expect(err).ToNot(HaveOccurred())
+			return loader.Load(expr)
+		}
+
+		It("allows imports from the allowed hosts", func() {
+			actual, err := loadRemote(Policy{AllowedHosts: []string{"127.0.0.1"}}, server.URL()+"/one.dhall")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(1)))
+		})
+		It("compares hosts case-insensitively", func() {
+			serverURL, err := url.Parse(server.URL())
+			Expect(err).ToNot(HaveOccurred())
+			port := serverURL.Port()
+			actual, err := loadRemote(Policy{AllowedHosts: []string{"LocalHost"}}, "http://localhost:"+port+"/one.dhall")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(1)))
+
+			actual, err = loadRemote(Policy{AllowedHosts: []string{"localhost:" + port}}, "http://LOCALHOST:"+port+"/one.dhall")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(1)))
+		})
+		It("forbids imports from other hosts", func() {
+			_, err := loadRemote(Policy{AllowedHosts: []string{"127.0.0.1:1"}}, server.URL()+"/one.dhall")
+
+			var policyErr *PolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			Expect(policyErr.Chain).To(HaveLen(1))
+			Expect(policyErr.Reason).To(HavePrefix("host 127.0.0.1:"))
+		})
+		It("describes a forbidden redirect without an import chain", func() {
+			err := &PolicyError{Reason: "redirect to http://localhost/: host localhost is not allowed"}
+
+			Expect(err).To(MatchError("Forbidden import: redirect to http://localhost/: host localhost is not allowed"))
+		})
+		It("forbids redirects to other hosts", func() {
+			_, err := loadRemote(Policy{AllowedHosts: []string{"127.0.0.1"}}, server.URL()+"/redirect.dhall")
+
+			var policyErr *PolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			Expect(policyErr.Chain).To(Equal([]Fetchable{remoteFile(server.URL() + "/redirect.dhall")}))
+			Expect(policyErr.Reason).To(HavePrefix("redirect to http://localhost:"))
+		})
+	})
+})
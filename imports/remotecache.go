@@ -0,0 +1,184 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wallyqs/dhall.go/binary"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// A RemoteCache is a cache for normalized Dhall expressions, stored in
+// binary form by an HTTP server, so that it can be shared between
+// machines.
+//
+// The protocol is simple: an entry is stored at the URL of the cache
+// followed by its hash, as a multihash in hexadecimal (for example
+// https://cache.example.com/dhall/1220d60d84…).  The RemoteCache
+// fetches an entry with a GET request to its URL, to which the server
+// responds with the binary-encoded expression, or 404 Not Found; it
+// saves an entry with a PUT request to its URL, with the
+// binary-encoded expression as the body.  Entries which don't match
+// their hash are ignored, so the server needn't be trusted.
+//
+// A RemoteCache is safe for concurrent use if its http.Client is.
+type RemoteCache struct {
+	url     string
+	client  *http.Client
+	onError func(error)
+}
+
+// CacheHTTPClient makes a RemoteCache make its requests with client,
+// for instance to add authentication, or a different timeout.  By
+// default, it uses a client which gives up on a request after
+// DefaultCacheTimeout.
+func CacheHTTPClient(client *http.Client) CacheOption {
+	return func(c *cacheConfig) { c.client = client }
+}
+
+// DefaultCacheTimeout is how long a RemoteCache waits for a response
+// by default; see CacheHTTPClient.
+const DefaultCacheTimeout = 30 * time.Second
+
+var defaultCacheClient = &http.Client{Timeout: DefaultCacheTimeout}
+
+// NewRemoteCache creates a new RemoteCache, with the cache store at
+// the given URL.
+func NewRemoteCache(url string, opts ...CacheOption) RemoteCache {
+	config := newCacheConfig(opts)
+	return RemoteCache{
+		url:     strings.TrimSuffix(url, "/"),
+		client:  config.client,
+		onError: config.onError,
+	}
+}
+
+func (r RemoteCache) entryURL(hash []byte) string {
+	return fmt.Sprintf("%s/%x", r.url, hash)
+}
+
+// Fetch fetches the term stored under hash from the RemoteCache.  If
+// the hash isn't in the cache, or its entry can't be fetched,
+// returns nil.
+func (r RemoteCache) Fetch(hash []byte) term.Term {
+	return r.FetchContext(context.Background(), hash)
+}
+
+// FetchContext is like Fetch, but gives up and returns nil once ctx
+// is done.
+func (r RemoteCache) FetchContext(ctx context.Context, hash []byte) term.Term {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.entryURL(hash), nil)
+	if err != nil {
+		r.onError(err)
+		return nil
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// a cancelled Load is not the cache's fault
+		if ctx.Err() == nil {
+			r.onError(err)
+		}
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		r.onError(fmt.Errorf("Got status %d from cache URL %s", resp.StatusCode, r.entryURL(hash)))
+		return nil
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		r.onError(err)
+		return nil
+	}
+	if sum := sha256.Sum256(content); len(hash) < 2 || !bytes.Equal(hash[2:], sum[:]) {
+		r.onError(fmt.Errorf("invalid cache entry for %x, ignoring", hash))
+		return nil
+	}
+	expr, err := binary.DecodeAsCbor(bytes.NewReader(content))
+	if err != nil {
+		r.onError(fmt.Errorf("invalid cache entry for %x: %w", hash, err))
+		return nil
+	}
+	return expr
+}
+
+// Save stores the given Term in the RemoteCache under the given hash.
+func (r RemoteCache) Save(hash []byte, e term.Term) {
+	r.SaveContext(context.Background(), hash, e)
+}
+
+// SaveContext is like Save, but gives up once ctx is done.
+func (r RemoteCache) SaveContext(ctx context.Context, hash []byte, e term.Term) {
+	if err := r.save(ctx, hash, e); err != nil && ctx.Err() == nil {
+		r.onError(fmt.Errorf("can't cache %x: %w", hash, err))
+	}
+}
+
+func (r RemoteCache) save(ctx context.Context, hash []byte, e term.Term) error {
+	var body bytes.Buffer
+	if err := binary.EncodeAsCbor(&body, e); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.entryURL(hash), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/cbor")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Got status %d from cache URL %s", resp.StatusCode, r.entryURL(hash))
+	}
+	return nil
+}
+
+// A CacheChain is a DhallCache made of several caches, typically a
+// LocalCache in front of a RemoteCache.  It fetches an entry from the
+// first cache which has it, and saves it to the caches before that
+// one; and it saves entries to all its caches.
+type CacheChain []DhallCache
+
+// Fetch fetches the term stored under hash from the first cache in
+// the CacheChain which has it.
+func (c CacheChain) Fetch(hash []byte) term.Term {
+	return c.FetchContext(context.Background(), hash)
+}
+
+// FetchContext is like Fetch, passing ctx to the caches in the
+// CacheChain which are ContextCaches.
+func (c CacheChain) FetchContext(ctx context.Context, hash []byte) term.Term {
+	for i, cache := range c {
+		if expr := fetchContext(ctx, cache, hash); expr != nil {
+			for _, earlier := range c[:i] {
+				saveContext(ctx, earlier, hash, expr)
+			}
+			return expr
+		}
+	}
+	return nil
+}
+
+// Save saves the given Term to each cache in the CacheChain.
+func (c CacheChain) Save(hash []byte, e term.Term) {
+	c.SaveContext(context.Background(), hash, e)
+}
+
+// SaveContext is like Save, passing ctx to the caches in the
+// CacheChain which are ContextCaches.
+func (c CacheChain) SaveContext(ctx context.Context, hash []byte, e term.Term) {
+	for _, cache := range c {
+		saveContext(ctx, cache, hash, e)
+	}
+}
@@ -0,0 +1,155 @@
+package imports_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	. "github.com/wallyqs/dhall.go/internal"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+// cacheServer serves a RemoteCache from memory under /cache.
+func cacheServer() (*ghttp.Server, map[string][]byte) {
+	var mu sync.Mutex
+	entries := map[string][]byte{}
+	server := ghttp.NewServer()
+	entryPath := regexp.MustCompile(`^/cache/[0-9a-f]+$`)
+	server.RouteToHandler("GET", entryPath, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		content, ok := entries[path.Base(r.URL.Path)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	})
+	server.RouteToHandler("PUT", entryPath, func(w http.ResponseWriter, r *http.Request) {
+		content, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		entries[path.Base(r.URL.Path)] = content
+		w.WriteHeader(http.StatusCreated)
+	})
+	return server, entries
+}
+
+var _ = Describe("RemoteCache", func() {
+	const entryName = "1220d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"
+	var server *ghttp.Server
+	var entries map[string][]byte
+	var errs []error
+	var cache RemoteCache
+	BeforeEach(func() {
+		server, entries = cacheServer()
+		errs = nil
+		cache = NewRemoteCache(server.URL()+"/cache/", CacheErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("saves and fetches entries", func() {
+		Expect(cache.Fetch(multihashOfOne)).To(BeNil())
+
+		cache.Save(multihashOfOne, NaturalLit(1))
+
+		Expect(entries).To(HaveKeyWithValue(entryName, []byte{0x82, 0x0f, 0x01}))
+		Expect(cache.Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+		Expect(errs).To(BeEmpty())
+	})
+	It("reports and ignores entries which don't match their hash", func() {
+		entries[entryName] = []byte{0x82, 0x0f, 0x02}
+
+		Expect(cache.Fetch(multihashOfOne)).To(BeNil())
+		Expect(errs).To(ConsistOf(MatchError(ContainSubstring("invalid cache entry"))))
+	})
+	It("reports failures to save", func() {
+		server.RouteToHandler("PUT", regexp.MustCompile(`^/read-only/`), ghttp.RespondWith(http.StatusForbidden, nil))
+		cache := NewRemoteCache(server.URL()+"/read-only", CacheErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+
+		cache.Save(multihashOfOne, NaturalLit(1))
+
+		Expect(errs).To(ConsistOf(MatchError(ContainSubstring("Got status 403"))))
+	})
+	It("serves frozen imports to a Loader", func() {
+		cache.Save(multihashOfOne, NaturalLit(1))
+		loader := NewLoader(WithCache(cache), WithEnv(map[string]string{}))
+
+		frozen := NewEnvVarImport("UNSET", Code)
+		frozen.Hash = multihashOfOne
+		actual, err := loader.Load(frozen)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NaturalLit(1)))
+	})
+	It("gives up on a hung server when the Load is cancelled", func() {
+		server.RouteToHandler("GET", regexp.MustCompile(`^/hung/`), func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		})
+		cache := NewRemoteCache(server.URL()+"/hung", CacheErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+		loader := NewLoader(WithCache(cache), WithEnv(map[string]string{}))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		frozen := NewEnvVarImport("UNSET", Code)
+		frozen.Hash = multihashOfOne
+		start := time.Now()
+		_, err := loader.LoadContext(ctx, frozen)
+
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(errs).To(BeEmpty())
+	})
+})
+
+var _ = Describe("CacheChain", func() {
+	var server *ghttp.Server
+	var entries map[string][]byte
+	var dir string
+	var chain CacheChain
+	BeforeEach(func() {
+		server, entries = cacheServer()
+		var err error
+		dir, err = ioutil.TempDir("", "dhall-cache")
+		Expect(err).ToNot(HaveOccurred())
+		chain = CacheChain{NewLocalCache(dir), NewRemoteCache(server.URL() + "/cache")}
+	})
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("saves entries to every cache", func() {
+		chain.Save(multihashOfOne, NaturalLit(1))
+
+		Expect(entries).To(HaveLen(1))
+		Expect(NewLocalCache(dir).Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+	})
+	It("fetches entries from the first cache which has them", func() {
+		NewRemoteCache(server.URL()+"/cache").Save(multihashOfOne, NaturalLit(1))
+
+		Expect(chain.Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+		Expect(NewLocalCache(dir).Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+
+		server.Close()
+		Expect(chain.Fetch(multihashOfOne)).To(Equal(NaturalLit(1)))
+	})
+})
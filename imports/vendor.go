@@ -0,0 +1,180 @@
+package imports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/wallyqs/dhall.go/binary"
+	"github.com/wallyqs/dhall.go/core"
+	. "github.com/wallyqs/dhall.go/term"
+)
+
+// VendorManifest is the name of the manifest in a vendor directory.
+const VendorManifest = "vendor.json"
+
+// A Vendor is a directory of vendored remote imports: their raw
+// source, as fetched, in files named after the hash of their key,
+// and a manifest, vendor.json, mapping their keys to those files.
+// An import's key is its URL, followed by ` using sha256:…` and the
+// semantic hash of the headers from its `using` clause, if it has
+// one, so that the same URL fetched with different headers is
+// vendored separately.  The headers are hashed rather than written
+// out, since they may hold credentials; user-supplied headers are
+// left out of the key altogether.  A Loader can fetch remote imports
+// from a Vendor instead of from the network; see WithVendor.
+type Vendor struct {
+	dir   string
+	files map[string]string
+}
+
+// OpenVendor opens the vendor directory dir, reading its manifest.
+func OpenVendor(dir string) (*Vendor, error) {
+	content, err := ioutil.ReadFile(path.Join(dir, VendorManifest))
+	if err != nil {
+		return nil, err
+	}
+	v := &Vendor{dir: dir}
+	if err := json.Unmarshal(content, &v.files); err != nil {
+		return nil, fmt.Errorf("%s: %w", path.Join(dir, VendorManifest), err)
+	}
+	return v, nil
+}
+
+// URLs returns the keys of the imports in the Vendor, in order.
+func (v *Vendor) URLs() []string {
+	urls := make([]string, 0, len(v.files))
+	for url := range v.files {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// fetch returns the vendored source of the import with the given
+// key, and whether it is vendored.
+func (v *Vendor) fetch(key string) (string, bool, error) {
+	file, ok := v.files[key]
+	if !ok {
+		return "", false, nil
+	}
+	content, err := ioutil.ReadFile(path.Join(v.dir, file))
+	if err != nil {
+		return "", true, err
+	}
+	return string(content), true, nil
+}
+
+// WithVendor makes the Loader fetch remote imports from v when they
+// are vendored there, and from the network otherwise.  Vendored
+// imports are checked against their semantic hashes, if any, as
+// usual.
+func WithVendor(v *Vendor) LoaderOption {
+	return func(l *Loader) { l.vendor, l.strictVendor = v, false }
+}
+
+// WithStrictVendor makes the Loader fetch remote imports only from
+// v, failing to resolve those which are not vendored there, so that
+// it never uses the network.
+func WithStrictVendor(v *Vendor) LoaderOption {
+	return func(l *Loader) { l.vendor, l.strictVendor = v, true }
+}
+
+// fetchVendored returns the source of the import with the given key
+// from the Loader's Vendor, and whether it came from there.  In
+// strict mode, an import which is not vendored is an error.
+func (l *Loader) fetchVendored(key string) (string, bool, error) {
+	if l.vendor == nil {
+		return "", false, nil
+	}
+	content, ok, err := l.vendor.fetch(key)
+	if !ok && l.strictVendor {
+		return "", false, fmt.Errorf("%s is not vendored in %s", key, l.vendor.dir)
+	}
+	return content, ok, err
+}
+
+// vendorKeyOf returns the key of remote in a Vendor, given using,
+// the resolved headers from its `using` clause, or nil.
+func vendorKeyOf(remote RemoteFile, using Term) (string, error) {
+	if using == nil {
+		return remote.String(), nil
+	}
+	hash, err := binary.SemanticHash(core.Eval(using))
+	if err != nil {
+		return "", err
+	}
+	// the hash is a multihash; skip its sha256 prefix
+	return fmt.Sprintf("%s using sha256:%x", remote, hash[2:]), nil
+}
+
+// Vendor resolves the imports of e, like Load, and stores the source
+// of every remote import it fetches, transitively, in the vendor
+// directory dir, adding them to its manifest.  The directory is
+// created if it doesn't exist.  Remote imports are fetched even if
+// they are cached, so that the Vendor is complete.  Vendor returns
+// the keys of the imports it stored, in order.
+func (l *Loader) Vendor(dir string, e Term, ancestors ...Fetchable) ([]string, error) {
+	return l.VendorContext(context.Background(), dir, e, ancestors...)
+}
+
+// VendorContext is like Vendor, but stops resolving imports and
+// returns ctx's error if ctx is done before resolution finishes.
+func (l *Loader) VendorContext(ctx context.Context, dir string, e Term, ancestors ...Fetchable) ([]string, error) {
+	v, err := OpenVendor(dir)
+	if os.IsNotExist(err) {
+		v, err = &Vendor{dir: dir, files: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if v.files == nil {
+		v.files = make(map[string]string)
+	}
+
+	uncached := *l
+	uncached.cache = NoCache{}
+	var mu sync.Mutex
+	fetched := make(map[string]string)
+	r := newResolver(&uncached, ctx)
+	r.onFetch = func(key, content string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched[key] = content
+	}
+	if _, _, err := r.load(e, ancestors...); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(fetched))
+	for url := range fetched {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		file := fmt.Sprintf("%x.dhall", sha256.Sum256([]byte(url)))
+		if err := writeFileAtomically(dir, file, []byte(fetched[url])); err != nil {
+			return nil, err
+		}
+		v.files[url] = file
+	}
+	manifest, err := json.MarshalIndent(v.files, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// write the manifest last, so that it only lists files which
+	// have been written in full
+	if err := writeFileAtomically(dir, VendorManifest, append(manifest, '\n')); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
@@ -0,0 +1,151 @@
+package imports_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/wallyqs/dhall.go/imports"
+	"github.com/wallyqs/dhall.go/parser"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Vendor", func() {
+	var server *ghttp.Server
+	var dir string
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/main.dhall", ghttp.RespondWith(http.StatusOK, "./one.dhall + ./two.dhall"))
+		server.RouteToHandler("GET", "/one.dhall", ghttp.RespondWith(http.StatusOK, "1"))
+		server.RouteToHandler("GET", "/two.dhall", ghttp.RespondWith(http.StatusOK, "2"))
+		server.RouteToHandler("GET", "/three.dhall", ghttp.RespondWith(http.StatusOK, "3"))
+		var err error
+		dir, err = ioutil.TempDir("", "dhall-vendor")
+		Expect(err).ToNot(HaveOccurred())
+		dir = filepath.Join(dir, "vendor")
+	})
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(filepath.Dir(dir))
+	})
+	parse := func(s string) Term {
+		expr, err := parser.Parse("-", []byte(s))
+		Expect(err).ToNot(HaveOccurred())
+		return expr
+	}
+	vendor := func(s string) []string {
+		urls, err := NewLoader(WithCache(NoCache{})).Vendor(dir, parse(s))
+		Expect(err).ToNot(HaveOccurred())
+		return urls
+	}
+
+	It("stores every remote import, transitively", func() {
+		urls := vendor(server.URL() + "/main.dhall")
+
+		Expect(urls).To(Equal([]string{
+			server.URL() + "/main.dhall",
+			server.URL() + "/one.dhall",
+			server.URL() + "/two.dhall",
+		}))
+		v, err := OpenVendor(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v.URLs()).To(Equal(urls))
+	})
+	It("leaves no temporary files behind", func() {
+		vendor(server.URL() + "/main.dhall")
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(4))
+		for _, file := range files {
+			Expect(file.Name()).To(Or(Equal(VendorManifest), HaveSuffix(".dhall")))
+			Expect(file.Mode().Perm()).To(Equal(os.FileMode(0644)))
+		}
+	})
+	It("adds to an existing vendor directory", func() {
+		vendor(server.URL() + "/one.dhall")
+		vendor(server.URL() + "/two.dhall")
+
+		v, err := OpenVendor(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v.URLs()).To(HaveLen(2))
+	})
+	It("stores imports even if they are cached", func() {
+		urls, err := NewLoader(WithCache(oneCache{})).Vendor(dir, parse(server.URL()+
+			"/one.dhall sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(urls).To(Equal([]string{server.URL() + "/one.dhall"}))
+	})
+	It("stores an import fetched with different headers separately", func() {
+		server.RouteToHandler("GET", "/greeting.dhall", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%q", r.Header.Get("X-Greeting"))
+		})
+		greeting := func(s string) string {
+			return fmt.Sprintf(`%s/greeting.dhall using [ { mapKey = "X-Greeting", mapValue = "%s" } ]`, server.URL(), s)
+		}
+		expr := "[ " + greeting("hello") + ", " + greeting("goodbye") + " ]"
+		urls := vendor(expr)
+
+		Expect(urls).To(HaveLen(2))
+		for _, url := range urls {
+			Expect(url).To(HavePrefix(server.URL() + "/greeting.dhall using sha256:"))
+		}
+		v, err := OpenVendor(dir)
+		Expect(err).ToNot(HaveOccurred())
+		server.Close()
+		actual, err := NewLoader(WithCache(NoCache{}), WithStrictVendor(v)).Load(parse(expr))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(NonEmptyList{PlainText("hello"), PlainText("goodbye")}))
+	})
+	Context("when loading", func() {
+		var v *Vendor
+		BeforeEach(func() {
+			vendor(server.URL() + "/main.dhall")
+			var err error
+			v, err = OpenVendor(dir)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fetches vendored imports without the network", func() {
+			main := server.URL() + "/main.dhall"
+			server.Close()
+			actual, err := NewLoader(WithCache(NoCache{}), WithStrictVendor(v)).Load(parse(main))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(3)))
+		})
+		It("fetches other imports from the network", func() {
+			actual, err := NewLoader(WithCache(NoCache{}), WithVendor(v)).Load(parse(server.URL() + "/three.dhall"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(NaturalLit(3)))
+		})
+		It("refuses other imports in strict mode", func() {
+			_, err := NewLoader(WithCache(NoCache{}), WithStrictVendor(v)).Load(parse(server.URL() + "/three.dhall"))
+
+			Expect(err).To(MatchError(server.URL() + "/three.dhall is not vendored in " + dir))
+		})
+		It("checks vendored imports against their hashes", func() {
+			// tamper with every vendored file
+			entries, err := ioutil.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			for _, entry := range entries {
+				if entry.Name() != VendorManifest {
+					Expect(ioutil.WriteFile(filepath.Join(dir, entry.Name()), []byte("0"), 0644)).To(Succeed())
+				}
+			}
+
+			_, err = NewLoader(WithCache(NoCache{}), WithStrictVendor(v)).Load(parse(server.URL() +
+				"/one.dhall sha256:d60d8415e36e86dae7f42933d3b0c4fe3ca238f057fba206c7e9fbf5d784fe15"))
+
+			Expect(err).To(MatchError(HavePrefix("Failed integrity check")))
+		})
+	})
+})
@@ -35,3 +35,10 @@ func NewRemoteImport(uri string, mode term.ImportMode) term.Import {
 	remote := term.NewRemoteFile(parsedURI)
 	return NewImport(remote, mode)
 }
+
+// only for generating test data - discards errors
+func NewRemoteImportUsing(uri string, headers term.Term, mode term.ImportMode) term.Import {
+	parsedURI, _ := url.ParseRequestURI(uri)
+	remote := term.NewRemoteFileUsing(parsedURI, headers)
+	return NewImport(remote, mode)
+}
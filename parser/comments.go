@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wallyqs/dhall.go/parser/internal"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// attachComments returns t with the comments from source attached to
+// its Notes.  A comment at the end of a line, after the end of a Note
+// on that line, or after the comma which follows it, is attached to
+// the outermost Note which ends there as one of its Line comments.  Other comments are attached to the
+// outermost Note which starts soonest after them as Leading comments;
+// those after the last Note are attached to t as Trailing comments.
+func attachComments(t term.Term, source string, comments map[int]internal.Comment) term.Term {
+	var starts, ends []int
+	var collect func(term.Term) term.Term
+	collect = func(t term.Term) term.Term {
+		if n, ok := t.(term.Note); ok {
+			starts = append(starts, n.Span.Start.Offset)
+			ends = append(ends, n.Span.End.Offset)
+		}
+		return term.TransformSubexprs(t, collect)
+	}
+	collect(t)
+	sort.Ints(starts)
+	sort.Ints(ends)
+
+	offsets := make([]int, 0, len(comments))
+	for offset := range comments {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	// a comment ends its line if only blanks, or other comments which
+	// end the line, follow it on the line
+	endsLine := make(map[int]bool)
+	for i := len(offsets) - 1; i >= 0; i-- {
+		comment := comments[offsets[i]]
+		next := skipBlanks(source, comment.End)
+		endsLine[offsets[i]] = strings.HasPrefix(comment.Text, "--") ||
+			next == len(source) || source[next] == '\n' || source[next] == '\r' ||
+			endsLine[next]
+	}
+
+	leading := make(map[int][]string)
+	line := make(map[int][]string)
+	// the end of the last comment at the end of each Note's line
+	lineEnd := make(map[int]int)
+	var trailing []string
+	for _, offset := range offsets {
+		comment := comments[offset]
+		if i := sort.SearchInts(ends, offset+1) - 1; i >= 0 && endsLine[offset] {
+			end := ends[i]
+			next := 0
+			if from, ok := lineEnd[end]; ok {
+				next = skipBlanks(source, from)
+			} else {
+				// the comment may follow the separator after the
+				// Note, as in [ 1, -- one
+				next = skipBlanks(source, end)
+				if next < len(source) && source[next] == ',' {
+					next = skipBlanks(source, next+1)
+				}
+			}
+			if next == offset {
+				line[end] = append(line[end], comment.Text)
+				lineEnd[end] = offset + len(comment.Text)
+				continue
+			}
+		}
+		i := sort.SearchInts(starts, comment.End)
+		if i == len(starts) {
+			trailing = append(trailing, comment.Text)
+			continue
+		}
+		leading[starts[i]] = append(leading[starts[i]], comment.Text)
+	}
+
+	var attach func(term.Term) term.Term
+	attach = func(t term.Term) term.Term {
+		if n, ok := t.(term.Note); ok {
+			// only the outermost Note gets the comments
+			var c term.Comments
+			if l, ok := leading[n.Span.Start.Offset]; ok {
+				c.Leading = l
+				delete(leading, n.Span.Start.Offset)
+			}
+			if l, ok := line[n.Span.End.Offset]; ok {
+				c.Line = l
+				delete(line, n.Span.End.Offset)
+			}
+			if c.Leading != nil || c.Line != nil {
+				n.Comments = &c
+			}
+			n.Term = attach(n.Term)
+			return n
+		}
+		return term.TransformSubexprs(t, attach)
+	}
+	t = attach(t)
+	if trailing != nil {
+		n, ok := t.(term.Note)
+		if !ok {
+			n = term.Note{Term: t}
+		}
+		var c term.Comments
+		if n.Comments != nil {
+			c = *n.Comments
+		}
+		c.Trailing = trailing
+		n.Comments = &c
+		t = n
+	}
+	return t
+}
+
+// skipBlanks returns the offset of the first character in source from
+// offset which isn't a space or a tab.
+func skipBlanks(source string, offset int) int {
+	for offset < len(source) && (source[offset] == ' ' || source[offset] == '\t') {
+		offset++
+	}
+	return offset
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"github.com/wallyqs/dhall.go/parser/internal"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// attachLayouts returns t with the Layout of each of its records and
+// unions, as written in source, attached to the Notes around them.
+func attachLayouts(t term.Term, source string, comments map[int]internal.Comment) term.Term {
+	// the end of the outermost Note starting at each offset, so that
+	// the scanner can skip over the values of fields
+	ends := make(map[int]int)
+	var collect func(term.Term) term.Term
+	collect = func(t term.Term) term.Term {
+		if n, ok := t.(term.Note); ok && n.Span.End.Offset > ends[n.Span.Start.Offset] {
+			ends[n.Span.Start.Offset] = n.Span.End.Offset
+		}
+		return term.TransformSubexprs(t, collect)
+	}
+	collect(t)
+
+	var attach func(term.Term) term.Term
+	attach = func(t term.Term) term.Term {
+		if n, ok := t.(term.Note); ok {
+			switch n.Term.(type) {
+			case term.RecordLit, term.RecordType, term.UnionType:
+				s := layoutScanner{source: source, comments: comments, ends: ends}
+				n.Layout = s.scan(n.Span.Start.Offset, n.Span.End.Offset)
+			}
+			n.Term = attach(n.Term)
+			return n
+		}
+		return term.TransformSubexprs(t, attach)
+	}
+	return attach(t)
+}
+
+// A layoutScanner reads the labels of the fields of a record or union
+// from its source text, skipping over their values.
+type layoutScanner struct {
+	source   string
+	comments map[int]internal.Comment
+	ends     map[int]int
+	pos      int
+}
+
+// scan returns the Layout of the record or union between start and
+// end, or nil if it has no fields, or its text is not as expected.
+func (s *layoutScanner) scan(start, end int) *term.Layout {
+	s.pos = start
+	var sep, close byte
+	switch s.next() {
+	case '{':
+		sep, close = ',', '}'
+	case '<':
+		sep, close = '|', '>'
+	default:
+		return nil
+	}
+	s.pos++
+	s.skip()
+	if s.next() == sep {
+		s.pos++
+	}
+	layout := &term.Layout{Puns: make(map[string]bool)}
+	seen := make(map[string]bool)
+	for {
+		s.skip()
+		if s.next() == close {
+			break
+		}
+		label, ok := s.label()
+		if !ok {
+			return nil
+		}
+		if !seen[label] {
+			seen[label] = true
+			layout.Fields = append(layout.Fields, label)
+		}
+		s.skip()
+		// the rest of a dotted label, as in { a.b = 1 }
+		for s.next() == '.' {
+			s.pos++
+			s.skip()
+			if _, ok := s.label(); !ok {
+				return nil
+			}
+			s.skip()
+		}
+		switch s.next() {
+		case '=', ':':
+			s.pos++
+			s.skip()
+			valueEnd, ok := s.ends[s.pos]
+			if !ok {
+				return nil
+			}
+			s.pos = valueEnd
+			s.skip()
+		case sep, close:
+			if sep == ',' {
+				layout.Puns[label] = true
+			}
+		default:
+			return nil
+		}
+		if s.next() == sep {
+			s.pos++
+		} else if s.next() != close {
+			return nil
+		}
+		if s.pos > end {
+			return nil
+		}
+	}
+	if len(layout.Fields) == 0 {
+		return nil
+	}
+	return layout
+}
+
+// next returns the byte at the scanner's position, or 0 at the end of
+// the source.
+func (s *layoutScanner) next() byte {
+	if s.pos >= len(s.source) {
+		return 0
+	}
+	return s.source[s.pos]
+}
+
+// skip skips whitespace and comments.
+func (s *layoutScanner) skip() {
+	for {
+		if c, ok := s.comments[s.pos]; ok {
+			s.pos = c.End
+			continue
+		}
+		switch s.next() {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// label reads a label, which may be quoted with backticks.
+func (s *layoutScanner) label() (string, bool) {
+	start := s.pos
+	if s.next() == '`' {
+		s.pos++
+		for s.pos < len(s.source) && s.source[s.pos] != '`' {
+			s.pos++
+		}
+		if s.pos >= len(s.source) {
+			return "", false
+		}
+		s.pos++
+		return s.source[start+1 : s.pos-1], true
+	}
+	for isLabelChar(s.next()) {
+		s.pos++
+	}
+	return s.source[start:s.pos], s.pos > start
+}
+
+func isLabelChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '/'
+}
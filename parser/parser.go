@@ -3,6 +3,8 @@ package parser
 import (
 	"errors"
 	"io"
+	"io/fs"
+	"io/ioutil"
 
 	"github.com/wallyqs/dhall.go/parser/internal"
 	"github.com/wallyqs/dhall.go/term"
@@ -10,10 +12,55 @@ import (
 
 //go:generate pigeon -optimize-grammar -optimize-parser -o internal/dhall.go internal/dhall.peg
 
+// An Option configures parsing.
+type Option func(*options)
+
+type options struct {
+	spans    bool
+	comments bool
+}
+
+// WithSpans makes the parser record where in the source each
+// subexpression came from, by wrapping it in a term.Note.  Type
+// errors in a Term parsed with spans report the file, line and
+// column of the failing subexpression.
+func WithSpans() Option {
+	return func(o *options) { o.spans = true }
+}
+
+// WithComments makes the parser keep the comments in the source, as
+// well as recording spans as WithSpans does.  Each comment is kept in
+// the term.Note of the subexpression which follows it, or, if it is at
+// the end of a line, of the subexpression which it follows on that
+// line; comments at the end of the source are kept in the outermost
+// term.Note.  It also records the order of the fields of each record
+// and union, and which record fields are puns, in the term.Layout of
+// its term.Note.  This is intended for tools, such as formatters,
+// which print a Term back out as source.
+func WithComments() Option {
+	return func(o *options) { o.comments = true }
+}
+
 // Parse parses the data from b using filename as information in the
 // error messages.
-func Parse(filename string, b []byte) (term.Term, error) {
-	result, err := internal.Parse(filename, b)
+func Parse(filename string, b []byte, opts ...Option) (term.Term, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var internalOpts []internal.Option
+	if o.spans || o.comments {
+		internalOpts = append(internalOpts,
+			internal.GlobalStore("filename", filename),
+			internal.GlobalStore("source", string(b)),
+		)
+	}
+	var comments map[int]internal.Comment
+	if o.comments {
+		comments = make(map[int]internal.Comment)
+		internalOpts = append(internalOpts, internal.GlobalStore("comments", comments))
+	}
+	result, err := internal.Parse(filename, b, internalOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -22,34 +69,37 @@ func Parse(filename string, b []byte) (term.Term, error) {
 		// can't happen if the PEG is correct
 		return nil, errors.New("dhall-golang internal error: parser returned a non-Term")
 	}
+	if o.comments {
+		term = attachLayouts(term, string(b), comments)
+		term = attachComments(term, string(b), comments)
+	}
 	return term, nil
 }
 
 // ParseFile parses the file identified by filename.
-func ParseFile(filename string) (term.Term, error) {
-	result, err := internal.ParseFile(filename)
+func ParseFile(filename string, opts ...Option) (term.Term, error) {
+	b, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	term, ok := result.(term.Term)
-	if !ok {
-		// can't happen if the PEG is correct
-		return nil, errors.New("dhall-golang internal error: parser returned a non-Term")
-	}
-	return term, nil
+	return Parse(filename, b, opts...)
 }
 
 // ParseReader parses the data from r using filename as information in
 // the error messages.
-func ParseReader(filename string, r io.Reader) (term.Term, error) {
-	result, err := internal.ParseReader(filename, r)
+func ParseReader(filename string, r io.Reader, opts ...Option) (term.Term, error) {
+	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	term, ok := result.(term.Term)
-	if !ok {
-		// can't happen if the PEG is correct
-		return nil, errors.New("dhall-golang internal error: parser returned a non-Term")
+	return Parse(filename, b, opts...)
+}
+
+// ParseFS parses the file identified by name in fsys.
+func ParseFS(fsys fs.FS, name string, opts ...Option) (term.Term, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
 	}
-	return term, nil
+	return Parse(name, b, opts...)
 }
@@ -0,0 +1,8 @@
+/*
+Package printer enables printing Terms as Dhall source.
+
+The printed source parses back to the same Term, and is laid out in
+the style of `dhall format`: expressions are kept on one line if they
+fit within the line width, and broken across lines otherwise.
+*/
+package printer
@@ -0,0 +1,193 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// A doc is a document to be laid out within a line width.  The
+// layout algorithm is Wadler's "prettier printer": each group is
+// printed flat (with its lines as spaces) if it fits in the
+// remaining width, and broken (with its lines as newlines)
+// otherwise.
+type doc interface{}
+
+type (
+	// text is printed as is.  It must not contain newlines.
+	text string
+
+	// A line is printed as flat in a flat group, and as a newline
+	// followed by the current indentation in a broken group.  A
+	// blank line is preceded by an empty line when broken; a hard
+	// line always breaks, so its group can never be flat.
+	line struct {
+		flat  string
+		blank bool
+		hard  bool
+	}
+
+	// rawNewline is a newline without indentation, for empty lines
+	// inside multi-line text literals.
+	rawNewline struct{}
+
+	// breakNext follows a comment at the end of a line: the text
+	// after it goes on a new line, even if no line comes first.
+	// Like a hard line, its group can never be flat.
+	breakNext struct{}
+
+	// cat is the concatenation of docs.
+	cat []doc
+
+	// nest indents the lines of doc by indent more than the
+	// enclosing doc.
+	nest struct {
+		indent int
+		doc    doc
+	}
+
+	// align indents the lines of doc to the column at which it
+	// starts.
+	align struct{ doc doc }
+
+	// group is printed flat if it fits, and broken otherwise.
+	group struct{ doc doc }
+
+	// alt is printed as flat in a flat group, and as broken in a
+	// broken group.
+	alt struct {
+		flat   doc
+		broken doc
+	}
+)
+
+var (
+	// softline is a line which is empty when flat.
+	softline = line{}
+	// spaceline is a line which is a space when flat.
+	spaceline = line{flat: " "}
+	// hardline is a line which always breaks.
+	hardline = line{hard: true}
+)
+
+// A cmd is a doc waiting to be laid out, with its indentation and
+// whether its enclosing group is flat.
+type cmd struct {
+	indent int
+	flat   bool
+	doc    doc
+}
+
+// layout lays out d within width columns.
+func layout(d doc, width int) string {
+	var out strings.Builder
+	col := 0
+	// set after a breakNext, until the next newline
+	mustBreak := false
+	stack := []cmd{{doc: d}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := c.doc.(type) {
+		case nil:
+		case text:
+			if mustBreak && d != "" {
+				out.WriteByte('\n')
+				out.WriteString(strings.Repeat(" ", c.indent))
+				col = c.indent
+				mustBreak = false
+			}
+			out.WriteString(string(d))
+			col += utf8.RuneCountInString(string(d))
+		case line:
+			if c.flat && !d.hard {
+				out.WriteString(d.flat)
+				col += utf8.RuneCountInString(d.flat)
+				continue
+			}
+			if d.blank {
+				out.WriteByte('\n')
+			}
+			out.WriteByte('\n')
+			out.WriteString(strings.Repeat(" ", c.indent))
+			col = c.indent
+			mustBreak = false
+		case rawNewline:
+			out.WriteByte('\n')
+			col = 0
+			mustBreak = false
+		case breakNext:
+			mustBreak = true
+		case cat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, cmd{c.indent, c.flat, d[i]})
+			}
+		case nest:
+			stack = append(stack, cmd{c.indent + d.indent, c.flat, d.doc})
+		case align:
+			stack = append(stack, cmd{col, c.flat, d.doc})
+		case group:
+			flat := c.flat || fits(width-col, cmd{c.indent, true, d.doc}, stack)
+			stack = append(stack, cmd{c.indent, flat, d.doc})
+		case alt:
+			if c.flat {
+				stack = append(stack, cmd{c.indent, true, d.flat})
+			} else {
+				stack = append(stack, cmd{c.indent, false, d.broken})
+			}
+		default:
+			panic("printer: unknown doc type")
+		}
+	}
+	return out.String()
+}
+
+// fits reports whether next, followed by rest up to its first broken
+// line, fits in width columns.
+func fits(width int, next cmd, rest []cmd) bool {
+	stack := []cmd{next}
+	for width >= 0 {
+		if len(stack) == 0 {
+			if len(rest) == 0 {
+				return true
+			}
+			stack = append(stack, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+		}
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := c.doc.(type) {
+		case nil:
+		case text:
+			width -= utf8.RuneCountInString(string(d))
+		case line:
+			if d.hard {
+				// a flat group can't contain a hard line; in a
+				// broken group, the line ends the text to fit
+				return !c.flat
+			}
+			if !c.flat {
+				return true
+			}
+			width -= utf8.RuneCountInString(d.flat)
+		case rawNewline, breakNext:
+			return !c.flat
+		case cat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, cmd{c.indent, c.flat, d[i]})
+			}
+		case nest:
+			stack = append(stack, cmd{c.indent + d.indent, c.flat, d.doc})
+		case align:
+			stack = append(stack, cmd{c.indent, c.flat, d.doc})
+		case group:
+			stack = append(stack, cmd{c.indent, c.flat, d.doc})
+		case alt:
+			if c.flat {
+				stack = append(stack, cmd{c.indent, true, d.flat})
+			} else {
+				stack = append(stack, cmd{c.indent, false, d.broken})
+			}
+		}
+	}
+	return false
+}
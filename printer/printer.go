@@ -0,0 +1,985 @@
+package printer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// An Option configures printing.
+type Option func(*printer)
+
+// ASCII makes the printer use ASCII syntax, such as `\` and `->`,
+// instead of Unicode syntax, such as `λ` and `→`.
+func ASCII() Option {
+	return func(p *printer) { p.ascii = true }
+}
+
+// Width sets the line width which the printer tries to keep within.
+// The default is 80.
+func Width(width int) Option {
+	return func(p *printer) { p.width = width }
+}
+
+// Fprint writes t to w as Dhall source.  It returns an error if t
+// contains something which has no Dhall syntax, such as a
+// term.LocalVar or a label with characters which can't be quoted.
+func Fprint(w io.Writer, t term.Term, opts ...Option) error {
+	s, err := Sprint(t, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, s)
+	return err
+}
+
+// Sprint returns t as Dhall source.  See Fprint.
+func Sprint(t term.Term, opts ...Option) (s string, err error) {
+	p := &printer{width: 80}
+	for _, opt := range opts {
+		opt(p)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(printError)
+			if !ok {
+				panic(r)
+			}
+			err = perr
+		}
+	}()
+	return layout(p.term(t), p.width), nil
+}
+
+// printError is the panic value which unwinds printing of an
+// unprintable Term back to Sprint.
+type printError struct{ msg string }
+
+func (e printError) Error() string { return "can't print Dhall: " + e.msg }
+
+func fail(format string, args ...interface{}) {
+	panic(printError{fmt.Sprintf(format, args...)})
+}
+
+type printer struct {
+	ascii bool
+	width int
+}
+
+// symbol returns unicode or ascii, according to the printer's
+// options.
+func (p *printer) symbol(unicode, ascii string) text {
+	if p.ascii {
+		return text(ascii)
+	}
+	return text(unicode)
+}
+
+// A level is a level of the Dhall grammar.  A Term at a lower level
+// must be parenthesized to appear where a higher level is expected.
+type level int
+
+const (
+	// expressionLevel is for lambdas, lets, annotations and other
+	// expressions which extend as far to the right as possible
+	expressionLevel level = iota
+	// operator levels, from the most loosely binding
+	equivalentLevel
+	importAltLevel
+	orLevel
+	plusLevel
+	textAppendLevel
+	listAppendLevel
+	andLevel
+	combineLevel
+	preferLevel
+	combineTypesLevel
+	timesLevel
+	equalLevel
+	notEqualLevel
+	applicationLevel
+	// importLevel is for imports and `::` completions
+	importLevel
+	selectorLevel
+	primitiveLevel
+)
+
+var opLevels = map[term.OpCode]level{
+	term.EquivOp:                  equivalentLevel,
+	term.ImportAltOp:              importAltLevel,
+	term.OrOp:                     orLevel,
+	term.PlusOp:                   plusLevel,
+	term.TextAppendOp:             textAppendLevel,
+	term.ListAppendOp:             listAppendLevel,
+	term.AndOp:                    andLevel,
+	term.RecordMergeOp:            combineLevel,
+	term.RightBiasedRecordMergeOp: preferLevel,
+	term.RecordTypeMergeOp:        combineTypesLevel,
+	term.TimesOp:                  timesLevel,
+	term.EqOp:                     equalLevel,
+	term.NeOp:                     notEqualLevel,
+	term.CompleteOp:               importLevel,
+}
+
+func levelOf(t term.Term) level {
+	switch t := t.(type) {
+	case term.Note:
+		return levelOf(t.Term)
+	case term.Lambda, term.Pi, term.Let, term.If, term.Annot,
+		term.With, term.EmptyList, term.Assert:
+		return expressionLevel
+	case term.Merge:
+		if t.Annotation != nil {
+			return expressionLevel
+		}
+		return applicationLevel
+	case term.ToMap:
+		if t.Type != nil {
+			return expressionLevel
+		}
+		return applicationLevel
+	case term.Op:
+		l, ok := opLevels[t.OpCode]
+		if !ok {
+			fail("unknown operator %d", t.OpCode)
+		}
+		return l
+	case term.App, term.Some, term.ShowConstructor:
+		return applicationLevel
+	case term.Import:
+		return importLevel
+	case term.Field, term.Project, term.ProjectType:
+		return selectorLevel
+	default:
+		return primitiveLevel
+	}
+}
+
+// unannotated reports whether t is a merge or toMap without a type
+// annotation of its own.
+func unannotated(t term.Term) bool {
+	switch t := t.(type) {
+	case term.Note:
+		return unannotated(t.Term)
+	case term.Merge:
+		return t.Annotation == nil
+	case term.ToMap:
+		return t.Type == nil
+	}
+	return false
+}
+
+// at returns the doc for t, parenthesized if it is below level l.
+func (p *printer) at(l level, t term.Term) doc {
+	if levelOf(t) < l {
+		return cat{text("("), align{p.term(t)}, text(")")}
+	}
+	return p.term(t)
+}
+
+func (p *printer) term(t term.Term) doc {
+	switch t := t.(type) {
+	case nil:
+		fail("nil Term")
+	case term.Note:
+		body := p.term(t.Term)
+		if t.Layout != nil {
+			body = p.fieldsOf(t.Term, t.Layout)
+		}
+		if t.Comments == nil {
+			return body
+		}
+		out := cat{comments(t.Comments.Leading), body, lineComments(t.Comments.Line)}
+		for _, c := range t.Comments.Trailing {
+			out = append(out, hardline, comment(c))
+		}
+		return out
+	case term.Universe:
+		return text(t.String())
+	case term.Builtin:
+		return text(string(t))
+	case term.BoolLit:
+		if t {
+			return text("True")
+		}
+		return text("False")
+	case term.Var:
+		if t.Index == 0 {
+			return text(nonreservedLabel(t.Name))
+		}
+		return text(fmt.Sprintf("%s@%d", nonreservedLabel(t.Name), t.Index))
+	case term.LocalVar:
+		fail("LocalVar %s has no Dhall syntax", t)
+	case term.Lambda:
+		return p.binder(p.symbol("λ", `\`), t.Label, t.Type, t.Body)
+	case term.Pi:
+		if t.Label == "_" {
+			return p.arrows(t)
+		}
+		return p.binder(p.symbol("∀", "forall "), t.Label, t.Type, t.Body)
+	case term.App:
+		return p.app(t)
+	case term.Op:
+		return p.op(t)
+	case term.Let:
+		return p.let(t)
+	case term.Annot:
+		// `merge a b : T` and `toMap a : T` would parse as an
+		// annotated merge or toMap, so an unannotated one needs
+		// parentheses
+		l := equivalentLevel
+		if unannotated(t.Expr) {
+			l = applicationLevel + 1
+		}
+		return group{cat{
+			p.at(l, t.Expr),
+			spaceline, text(": "), align{p.term(t.Annotation)},
+		}}
+	case term.If:
+		return group{cat{
+			text("if "), align{p.term(t.Cond)},
+			spaceline, text("then "), align{p.term(t.T)},
+			spaceline, text("else "), align{p.term(t.F)},
+		}}
+	case term.NaturalLit:
+		return text(fmt.Sprint(uint(t)))
+	case term.BigNaturalLit:
+		return text(t.Int.String())
+	case term.IntegerLit:
+		return text(fmt.Sprintf("%+d", int(t)))
+	case term.BigIntegerLit:
+		if t.Int.Sign() >= 0 {
+			return text("+" + t.Int.String())
+		}
+		return text(t.Int.String())
+	case term.DoubleLit:
+		return text(double(float64(t)))
+	case term.TextLit:
+		return p.textLit(t)
+	case term.BytesLit:
+		return text(`0x"` + strings.ToUpper(hex.EncodeToString(t)) + `"`)
+	case term.DateLit:
+		return text(t.String())
+	case term.TimeLit:
+		return text(t.String())
+	case term.TimeZoneLit:
+		return text(t.String())
+	case term.EmptyList:
+		return group{cat{
+			text("[] :"),
+			nest{2, cat{spaceline, p.at(applicationLevel, t.Type)}},
+		}}
+	case term.NonEmptyList:
+		elements := make([]doc, len(t))
+		for i, e := range t {
+			elements[i] = p.term(e)
+		}
+		return p.enclosed("[", ",", "]", elements)
+	case term.Some:
+		return p.prefixed(text("Some"), t.Val)
+	case term.ShowConstructor:
+		return p.prefixed(text("showConstructor"), t.Expr)
+	case term.RecordType, term.RecordLit, term.UnionType:
+		return p.fieldsOf(t, nil)
+	case term.ToMap:
+		toMap := p.prefixed(text("toMap"), t.Record)
+		if t.Type == nil {
+			return toMap
+		}
+		return group{cat{
+			toMap, spaceline, text(": "), align{p.at(applicationLevel, t.Type)},
+		}}
+	case term.Merge:
+		merge := group{cat{
+			text("merge"),
+			nest{2, cat{
+				spaceline, p.at(importLevel, t.Handler),
+				spaceline, p.at(importLevel, t.Union),
+			}},
+		}}
+		if t.Annotation == nil {
+			return merge
+		}
+		return group{cat{
+			merge, spaceline, text(": "), align{p.at(applicationLevel, t.Annotation)},
+		}}
+	case term.Field:
+		return cat{p.at(selectorLevel, t.Record), text("." + anyLabel(t.FieldName))}
+	case term.Project:
+		labels := make([]string, len(t.FieldNames))
+		for i, name := range t.FieldNames {
+			labels[i] = anyLabelOrSome(name)
+		}
+		projection := "{}"
+		if len(labels) > 0 {
+			projection = "{ " + strings.Join(labels, ", ") + " }"
+		}
+		return cat{p.at(selectorLevel, t.Record), text("." + projection)}
+	case term.ProjectType:
+		return cat{
+			p.at(selectorLevel, t.Record),
+			text(".("), align{p.term(t.Selector)}, text(")"),
+		}
+	case term.Assert:
+		return cat{text("assert : "), align{p.term(t.Annotation)}}
+	case term.With:
+		return p.with(t)
+	case term.Import:
+		return p.importTerm(t)
+	default:
+		fail("unknown Term type %T", t)
+	}
+	return nil
+}
+
+// binder returns the doc for a lambda or forall.
+func (p *printer) binder(symbol text, label string, typ, body term.Term) doc {
+	return group{cat{
+		symbol, text("(" + nonreservedLabel(label) + " : "), align{p.term(typ)}, text(") "),
+		p.symbol("→", "->"),
+		nest{2, cat{spaceline, p.term(body)}},
+	}}
+}
+
+// arrows returns the doc for a chain of non-dependent function
+// types.
+func (p *printer) arrows(pi term.Pi) doc {
+	var out cat
+	var t term.Term = pi
+	for {
+		pi, ok := stripNote(t).(term.Pi)
+		if !ok || pi.Label != "_" {
+			break
+		}
+		out = append(out, p.at(equivalentLevel, pi.Type), spaceline, p.symbol("→ ", "-> "))
+		t = pi.Body
+	}
+	out = append(out, align{p.term(t)})
+	return group{out}
+}
+
+func (p *printer) app(app term.App) doc {
+	var args []term.Term
+	var fn term.Term = app
+	for {
+		a, ok := stripNote(fn).(term.App)
+		if !ok {
+			break
+		}
+		args = append(args, a.Arg)
+		fn = a.Fn
+	}
+	var rest cat
+	for i := len(args) - 1; i >= 0; i-- {
+		rest = append(rest, spaceline, p.at(importLevel, args[i]))
+	}
+	return group{cat{p.at(applicationLevel, fn), nest{2, rest}}}
+}
+
+// prefixed returns the doc for a keyword applied to an argument,
+// such as `Some x`.
+func (p *printer) prefixed(keyword text, arg term.Term) doc {
+	return group{cat{keyword, nest{2, cat{spaceline, p.at(importLevel, arg)}}}}
+}
+
+func (p *printer) operator(op term.OpCode) text {
+	switch op {
+	case term.OrOp:
+		return "||"
+	case term.AndOp:
+		return "&&"
+	case term.EqOp:
+		return "=="
+	case term.NeOp:
+		return "!="
+	case term.PlusOp:
+		return "+"
+	case term.TimesOp:
+		return "*"
+	case term.TextAppendOp:
+		return "++"
+	case term.ListAppendOp:
+		return "#"
+	case term.RecordMergeOp:
+		return p.symbol("∧", `/\`)
+	case term.RightBiasedRecordMergeOp:
+		return p.symbol("⫽", "//")
+	case term.RecordTypeMergeOp:
+		return p.symbol("⩓", `//\\`)
+	case term.ImportAltOp:
+		return "?"
+	case term.EquivOp:
+		return p.symbol("≡", "===")
+	case term.CompleteOp:
+		return "::"
+	}
+	fail("unknown operator %d", op)
+	return ""
+}
+
+func (p *printer) op(op term.Op) doc {
+	if op.OpCode == term.CompleteOp {
+		return cat{
+			p.at(selectorLevel, op.L), text("::"), p.at(selectorLevel, op.R),
+		}
+	}
+	l := opLevels[op.OpCode]
+	// operators are left associative, so collect the chain of
+	// operands down the left
+	operands := []term.Term{op.R}
+	var t term.Term = op.L
+	for {
+		next, ok := stripNote(t).(term.Op)
+		if !ok || next.OpCode != op.OpCode {
+			break
+		}
+		operands = append(operands, next.R)
+		t = next.L
+	}
+	out := cat{p.at(l, t)}
+	symbol := p.operator(op.OpCode)
+	for i := len(operands) - 1; i >= 0; i-- {
+		out = append(out,
+			spaceline, symbol, text(" "), align{p.at(l+1, operands[i])})
+	}
+	return group{out}
+}
+
+func (p *printer) let(let term.Let) doc {
+	var out cat
+	separator := line{flat: " ", blank: true}
+	if len(let.Bindings) > 1 {
+		separator.hard = true
+	}
+	for _, b := range let.Bindings {
+		before, value, after := hoistComments(b.Value)
+		binding := cat{comments(before), text("let " + nonreservedLabel(b.Variable))}
+		if b.Annotation != nil {
+			binding = append(binding, text(" : "), align{p.term(b.Annotation)})
+		}
+		binding = append(binding,
+			text(" ="), group{nest{2, cat{spaceline, p.term(value)}}}, lineComments(after))
+		out = append(out, binding, separator)
+	}
+	out = append(out,
+		text("in"), alt{text(" "), text("  ")}, align{p.term(let.Body)})
+	return group{out}
+}
+
+func (p *printer) with(with term.With) doc {
+	var clauses []term.With
+	var t term.Term = with
+	for {
+		w, ok := stripNote(t).(term.With)
+		if !ok {
+			break
+		}
+		clauses = append(clauses, w)
+		t = w.Record
+	}
+	var rest cat
+	for i := len(clauses) - 1; i >= 0; i-- {
+		path := make([]string, len(clauses[i].Path))
+		for j, label := range clauses[i].Path {
+			path[j] = anyLabelOrSome(label)
+		}
+		rest = append(rest,
+			spaceline, text("with "+strings.Join(path, ".")+" = "),
+			align{p.at(equivalentLevel, clauses[i].Value)})
+	}
+	return group{cat{p.at(importLevel, t), nest{2, rest}}}
+}
+
+// fieldsOf returns the doc for t, a record type, record literal or
+// union type, with its fields as laid out by layout, if it is not nil.
+func (p *printer) fieldsOf(t term.Term, layout *term.Layout) doc {
+	switch t := t.(type) {
+	case term.RecordType:
+		if len(t) == 0 {
+			return text("{}")
+		}
+		return p.enclosed("{", ",", "}", p.fields(t, " :", layout))
+	case term.RecordLit:
+		if len(t) == 0 {
+			return text("{=}")
+		}
+		return p.enclosed("{", ",", "}", p.fields(t, " =", layout))
+	case term.UnionType:
+		if len(t) == 0 {
+			return text("<>")
+		}
+		return p.enclosed("<", "|", ">", p.fields(t, " :", layout))
+	}
+	return p.term(t)
+}
+
+// fields returns the docs for the fields of a record type, record
+// literal or union type, in the order of layout if it has them all,
+// and in sorted order otherwise.  sep separates each label from its
+// value; union alternatives with a nil value have no sep, and nor do
+// the fields which layout records as puns.
+func (p *printer) fields(fields map[string]term.Term, sep string, layout *term.Layout) []doc {
+	labels := make([]string, 0, len(fields))
+	if layout != nil && len(layout.Fields) == len(fields) {
+		for _, label := range layout.Fields {
+			if _, ok := fields[label]; ok {
+				labels = append(labels, label)
+			}
+		}
+	}
+	if len(labels) != len(fields) {
+		labels = labels[:0]
+		for label := range fields {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+	}
+	out := make([]doc, len(labels))
+	for i, label := range labels {
+		if fields[label] == nil || layout != nil && layout.Puns[label] && fields[label] == term.NewVar(label) {
+			out[i] = text(anyLabelOrSome(label))
+			continue
+		}
+		before, value, after := hoistComments(fields[label])
+		out[i] = cat{
+			comments(before),
+			text(anyLabelOrSome(label) + sep),
+			group{nest{2, cat{spaceline, p.term(value)}}},
+			lineComments(after),
+		}
+	}
+	return out
+}
+
+// enclosed returns the doc for the elements of a list, record or
+// union between open and close, separated by sep.  When broken, each
+// element goes on its own line, with the separator in front:
+//
+//	{ a = 1
+//	, b = 2
+//	}
+func (p *printer) enclosed(open, sep, close string, elements []doc) doc {
+	out := cat{text(open + " ")}
+	// a comma follows its element, but other separators are spaced
+	before := spaceline
+	if sep == "," {
+		before = softline
+	}
+	for i, e := range elements {
+		if i > 0 {
+			out = append(out, before, text(sep+" "))
+		}
+		out = append(out, align{e})
+	}
+	out = append(out, spaceline, text(close))
+	return group{align{out}}
+}
+
+func (p *printer) textLit(t term.TextLit) doc {
+	if d := p.multilineText(t); d != nil {
+		return d
+	}
+	out := cat{text(`"`)}
+	for _, chunk := range t.Chunks {
+		out = append(out,
+			text(escapeText(chunk.Prefix)+"${"), align{p.term(chunk.Expr)}, text("}"))
+	}
+	out = append(out, text(escapeText(t.Suffix)+`"`))
+	return group{out}
+}
+
+// double returns the Double literal for f.
+func double(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		// a whole number needs a decimal point to be a Double
+		s += ".0"
+	}
+	return s
+}
+
+// escapeText escapes s for a double-quoted text literal.  A `$` is
+// only escaped if it would start an interpolation.
+func escapeText(s string) string {
+	var out strings.Builder
+	for i, r := range s {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				out.WriteString(`\$`)
+			} else {
+				out.WriteRune(r)
+			}
+		case '\b':
+			out.WriteString(`\b`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&out, `\u%04X`, r)
+			} else if r < 0x80 || validNonASCII(r) {
+				out.WriteRune(r)
+			} else {
+				fail("text contains invalid character %U", r)
+			}
+		}
+	}
+	return out.String()
+}
+
+// validNonASCII reports whether r can appear unescaped in Dhall
+// source.
+func validNonASCII(r rune) bool {
+	switch {
+	case r == 0xFFFD:
+		// utf8.RuneError, from invalid UTF-8
+		return false
+	case r >= 0x80 && r <= 0xD7FF:
+		return true
+	case r >= 0xE000 && r <= 0x10FFFD:
+		return r&0xFFFF <= 0xFFFD
+	}
+	return false
+}
+
+// multilineText returns the doc for t as a single-quoted literal, or
+// nil if t is better (or only) printed double-quoted.
+//
+// The parser removes the common indentation of the lines of a
+// single-quoted literal, so the first line must start with text
+// rather than whitespace or an interpolation: this pins the common
+// indentation to that of the literal itself.
+func (p *printer) multilineText(t term.TextLit) doc {
+	parts := make([]string, 0, len(t.Chunks)+1)
+	for _, chunk := range t.Chunks {
+		parts = append(parts, chunk.Prefix)
+	}
+	parts = append(parts, t.Suffix)
+	if !strings.Contains(strings.Join(parts, ""), "\n") ||
+		parts[0] == "" || strings.ContainsAny(parts[0][:1], " \t\n") ||
+		// the closing quotes would make an escaped quote pair
+		strings.HasSuffix(t.Suffix, "'") {
+		return nil
+	}
+	for _, part := range parts {
+		// an escaped interpolation after a quote would make an
+		// escaped quote pair
+		if strings.Contains(part, "'${") {
+			return nil
+		}
+		for _, r := range part {
+			if r < 0x20 && r != '\n' && r != '\t' || r >= 0x80 && !validNonASCII(r) {
+				return nil
+			}
+		}
+	}
+	// the text is printed one line at a time, so that each line is
+	// indented to the literal's level
+	out := cat{text("''"), hardline}
+	addLines := func(s string) {
+		lines := strings.Split(s, "\n")
+		for i, l := range lines {
+			if i > 0 {
+				if lines[i] == "" && i < len(lines)-1 {
+					out = append(out, rawNewline{})
+					continue
+				}
+				out = append(out, hardline)
+			}
+			out = append(out, text(escapeSingleQuoted(l)))
+		}
+	}
+	for _, chunk := range t.Chunks {
+		addLines(chunk.Prefix)
+		out = append(out, text("${"), align{p.term(chunk.Expr)}, text("}"))
+	}
+	addLines(t.Suffix)
+	out = append(out, text("''"))
+	return out
+}
+
+// escapeSingleQuoted escapes s for a single-quoted text literal.
+func escapeSingleQuoted(s string) string {
+	s = strings.ReplaceAll(s, "''", "'''")
+	return strings.ReplaceAll(s, "${", "''${")
+}
+
+func (p *printer) importTerm(i term.Import) doc {
+	out := cat{text(fetchable(i.Fetchable))}
+	if remote, ok := i.Fetchable.(term.RemoteFile); ok && remote.Headers() != nil {
+		headers := p.at(importLevel, remote.Headers())
+		if _, ok := stripNote(remote.Headers()).(term.Import); ok {
+			// otherwise the headers would take this import's hash
+			// and mode as their own
+			headers = cat{text("("), headers, text(")")}
+		}
+		out = append(out, text(" using "), headers)
+	}
+	if i.Hash != nil {
+		if len(i.Hash) != 34 {
+			fail("import hash must be a 34-byte multihash")
+		}
+		out = append(out, text(" sha256:"+hex.EncodeToString(i.Hash[2:])))
+	}
+	switch i.ImportMode {
+	case term.RawText:
+		out = append(out, text(" as Text"))
+	case term.RawBytes:
+		out = append(out, text(" as Bytes"))
+	case term.Location:
+		out = append(out, text(" as Location"))
+	}
+	return out
+}
+
+var (
+	bashEnvVar    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	pathComponent = regexp.MustCompile("^[!$-'*+\\-.0-;=@-Z^-z|~]+$")
+)
+
+func fetchable(f term.Fetchable) string {
+	switch f := f.(type) {
+	case term.Missing:
+		return "missing"
+	case term.EnvVar:
+		if bashEnvVar.MatchString(string(f)) {
+			return "env:" + string(f)
+		}
+		return `env:"` + escapePosix(string(f)) + `"`
+	case term.LocalFile:
+		var prefix string
+		path := string(f)
+		switch {
+		case f.IsAbs():
+			path = path[1:]
+		case strings.HasPrefix(path, "~/"):
+			prefix, path = "~", path[2:]
+		case strings.HasPrefix(path, "../"):
+			prefix, path = "..", path[3:]
+		default:
+			prefix = "."
+		}
+		var out strings.Builder
+		out.WriteString(prefix)
+		for _, component := range strings.Split(path, "/") {
+			out.WriteByte('/')
+			if pathComponent.MatchString(component) {
+				out.WriteString(component)
+			} else {
+				out.WriteString(`"` + quotePathComponent(component) + `"`)
+			}
+		}
+		return out.String()
+	case term.RemoteFile:
+		return f.String()
+	}
+	fail("unknown import type %T", f)
+	return ""
+}
+
+// quotePathComponent checks that component can be written in a
+// quoted path component, and returns it.
+func quotePathComponent(component string) string {
+	for _, r := range component {
+		if r < 0x20 || r == '"' || r == '/' || r >= 0x80 && !validNonASCII(r) {
+			fail("path component %q can't be quoted", component)
+		}
+	}
+	if component == "" {
+		fail("empty path component")
+	}
+	return component
+}
+
+// escapePosix escapes s for a quoted `env:"…"` import.
+func escapePosix(s string) string {
+	var out strings.Builder
+	for _, r := range s {
+		switch r {
+		case '"':
+			out.WriteString(`\"`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '\a':
+			out.WriteString(`\a`)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\v':
+			out.WriteString(`\v`)
+		default:
+			if r < 0x20 || r > 0x7e || r == '=' {
+				fail("environment variable name %q can't be quoted", s)
+			}
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// comment returns the doc for a comment.  The lines of a block
+// comment are kept as they are, without reindenting.
+func comment(c string) doc {
+	var out cat
+	for i, l := range strings.Split(c, "\n") {
+		if i > 0 {
+			out = append(out, rawNewline{})
+		}
+		out = append(out, text(strings.TrimSuffix(l, "\r")))
+	}
+	return out
+}
+
+// comments returns the doc for the comments before a Term, each on
+// its own line.
+func comments(cs []string) doc {
+	var out cat
+	for _, c := range cs {
+		out = append(out, comment(c), hardline)
+	}
+	return out
+}
+
+// lineComments returns the doc for the comments at the end of a
+// line, after a Term.
+func lineComments(cs []string) doc {
+	if len(cs) == 0 {
+		return nil
+	}
+	var out cat
+	for _, c := range cs {
+		out = append(out, text(" "), comment(c))
+	}
+	return append(out, breakNext{})
+}
+
+// hoistComments returns the comments before t, t without its
+// comments, and the comments at the end of its line, so that they
+// can be printed around the field or let binding whose value is t.
+func hoistComments(t term.Term) ([]string, term.Term, []string) {
+	n, ok := t.(term.Note)
+	if !ok || n.Comments == nil {
+		return nil, t, nil
+	}
+	// the Comments may be shared, so leave them be
+	c := *n.Comments
+	before, after := c.Leading, c.Line
+	c.Leading, c.Line = nil, nil
+	n.Comments = &c
+	return before, n, after
+}
+
+func stripNote(t term.Term) term.Term {
+	for {
+		n, ok := t.(term.Note)
+		if !ok {
+			return t
+		}
+		t = n.Term
+	}
+}
+
+var simpleLabel = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_/-]*$`)
+
+var keywords = map[string]bool{
+	"if": true, "then": true, "else": true,
+	"let": true, "in": true,
+	"using": true, "missing": true,
+	"assert": true, "as": true,
+	"Infinity": true, "NaN": true,
+	"merge": true, "Some": true, "toMap": true,
+	"forall":          true,
+	"with":            true,
+	"showConstructor": true,
+}
+
+var builtins = map[string]bool{
+	"True": true, "False": true,
+	"Type": true, "Kind": true, "Sort": true,
+}
+
+func init() {
+	for _, b := range []term.Builtin{
+		term.Double, term.Text, term.Bool, term.Natural, term.Integer,
+		term.List, term.Optional, term.None, term.Date, term.Time,
+		term.TimeZone, term.Bytes,
+		term.NaturalBuild, term.NaturalFold, term.NaturalIsZero,
+		term.NaturalEven, term.NaturalOdd, term.NaturalToInteger,
+		term.NaturalShow, term.NaturalSubtract,
+		term.IntegerClamp, term.IntegerNegate, term.IntegerToDouble,
+		term.IntegerShow,
+		term.DoubleShow, term.TextShow, term.TextReplace,
+		term.DateShow, term.TimeShow, term.TimeZoneShow, term.BytesShow,
+		term.ListBuild, term.ListFold, term.ListLength, term.ListHead,
+		term.ListLast, term.ListIndexed, term.ListReverse,
+	} {
+		builtins[string(b)] = true
+	}
+}
+
+// anyLabel returns label as it must be written to select a field.
+func anyLabel(label string) string {
+	if simpleLabel.MatchString(label) && !keywords[label] {
+		return label
+	}
+	return quoteLabel(label)
+}
+
+// anyLabelOrSome returns label as it must be written as a record
+// field or union alternative, where `Some` needn't be quoted.
+func anyLabelOrSome(label string) string {
+	if label == "Some" {
+		return label
+	}
+	return anyLabel(label)
+}
+
+// nonreservedLabel returns label as it must be written as a
+// variable.
+func nonreservedLabel(label string) string {
+	if simpleLabel.MatchString(label) && !keywords[label] && !builtins[label] {
+		return label
+	}
+	return quoteLabel(label)
+}
+
+func quoteLabel(label string) string {
+	for _, r := range label {
+		if r < 0x20 || r > 0x7e || r == '`' {
+			fail("label %q can't be quoted", label)
+		}
+	}
+	return "`" + label + "`"
+}
@@ -0,0 +1,220 @@
+package printer_test
+
+import (
+	"bytes"
+
+	. "github.com/wallyqs/dhall.go/internal"
+	"github.com/wallyqs/dhall.go/parser"
+	"github.com/wallyqs/dhall.go/printer"
+	. "github.com/wallyqs/dhall.go/term"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func PrintAndCompare(t Term, expected string, opts ...printer.Option) {
+	Expect(printer.Sprint(t, opts...)).To(Equal(expected))
+}
+
+func op(opCode OpCode, l, r Term) Op {
+	return Op{OpCode: opCode, L: l, R: r}
+}
+
+// RoundTrip checks that source is printed as itself, and so that
+// printing then parsing gives back the parsed Term.
+func RoundTrip(source string, opts ...printer.Option) {
+	t, err := parser.Parse("test", []byte(source))
+	Expect(err).ToNot(HaveOccurred())
+	printed, err := printer.Sprint(t, opts...)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(printed).To(Equal(source))
+	Expect(parser.Parse("test", []byte(printed))).To(Equal(t))
+}
+
+var _ = Describe("Sprint", func() {
+	DescribeTable("terms", PrintAndCompare,
+		Entry("Type", Type, `Type`),
+		Entry("Natural", Natural, `Natural`),
+		Entry("variable", NewVar("x"), `x`),
+		Entry("variable with index", Var{Name: "x", Index: 2}, `x@2`),
+		Entry("keyword variable", NewVar("if"), "`if`"),
+		Entry("builtin variable", NewVar("Natural"), "`Natural`"),
+		Entry("NaturalLit", NaturalLit(3), `3`),
+		Entry("IntegerLit", IntegerLit(-3), `-3`),
+		Entry("positive IntegerLit", IntegerLit(3), `+3`),
+		Entry("DoubleLit", DoubleLit(1.5), `1.5`),
+		Entry("whole DoubleLit", DoubleLit(2), `2.0`),
+		Entry("large DoubleLit", DoubleLit(1.5e10), `1.5e+10`),
+		Entry("BytesLit", BytesLit{0, 0xff}, `0x"00FF"`),
+		Entry("lambda",
+			NewLambda("x", Natural, NewVar("x")),
+			`λ(x : Natural) → x`),
+		Entry("function type",
+			NewPi("_", Natural, Natural),
+			`Natural → Natural`),
+		Entry("higher-order function type",
+			NewPi("_", NewPi("_", Natural, Natural), Natural),
+			`(Natural → Natural) → Natural`),
+		Entry("dependent function type",
+			NewPi("a", Type, NewPi("_", NewVar("a"), NewVar("a"))),
+			`∀(a : Type) → a → a`),
+		Entry("left-nested operators",
+			op(PlusOp, op(PlusOp, NaturalLit(1), NaturalLit(2)), NaturalLit(3)),
+			`1 + 2 + 3`),
+		Entry("right-nested operators",
+			op(PlusOp, NaturalLit(1), op(PlusOp, NaturalLit(2), NaturalLit(3))),
+			`1 + (2 + 3)`),
+		Entry("looser operator inside tighter one",
+			op(TimesOp, op(PlusOp, NaturalLit(1), NaturalLit(2)), NaturalLit(3)),
+			`(1 + 2) * 3`),
+		Entry("tighter operator inside looser one",
+			op(PlusOp, op(TimesOp, NaturalLit(1), NaturalLit(2)), NaturalLit(3)),
+			`1 * 2 + 3`),
+		Entry("application of lambda",
+			Apply(NewLambda("x", Natural, NewVar("x")), NaturalLit(1)),
+			`(λ(x : Natural) → x) 1`),
+		Entry("nested Some",
+			Some{Some{NaturalLit(1)}},
+			`Some (Some 1)`),
+		Entry("field of keyword",
+			Field{NewVar("r"), "Some"},
+			"r.`Some`"),
+		Entry("record with keyword fields",
+			RecordLit{"Some": NaturalLit(1), "if": NaturalLit(2)},
+			"{ Some = 1, `if` = 2 }"),
+		Entry("empty record", RecordLit{}, `{=}`),
+		Entry("empty record type", RecordType{}, `{}`),
+		Entry("empty union", UnionType{}, `<>`),
+		Entry("union",
+			UnionType{"A": Natural, "B": nil},
+			`< A : Natural | B >`),
+		Entry("empty list",
+			EmptyList{Apply(List, Natural)},
+			`[] : List Natural`),
+		Entry("text with escapes",
+			PlainText("\"\\${ \t\n\u0007"),
+			`"\"\\\${ \t\n\u0007"`),
+		Entry("interpolated text",
+			TextLit{Chunks: Chunks{{Prefix: "a", Expr: NewVar("x")}}, Suffix: "b"},
+			`"a${x}b"`),
+		Entry("multi-line text",
+			PlainText("a\n  b ''\n"),
+			"''\na\n  b '''\n''"),
+		Entry("local import",
+			NewImport(LocalFile("foo.dhall"), Code),
+			`./foo.dhall`),
+		Entry("local import with space",
+			NewImport(LocalFile("/a b/c"), RawText),
+			`/"a b"/c as Text`),
+		Entry("environment variable import",
+			NewImport(EnvVar("HOME"), Location),
+			`env:HOME as Location`),
+		Entry("missing", NewImport(Missing{}, Code), `missing`),
+	)
+	DescribeTable("ASCII", func(t Term, expected string) {
+		PrintAndCompare(t, expected, printer.ASCII())
+	},
+		Entry("lambda",
+			NewLambda("x", Natural, NewVar("x")),
+			`\(x : Natural) -> x`),
+		Entry("forall",
+			NewPi("a", Type, NewVar("a")),
+			`forall (a : Type) -> a`),
+		Entry("operators",
+			op(RecordMergeOp, op(RightBiasedRecordMergeOp, NewVar("a"), NewVar("b")), NewVar("c")),
+			`a // b /\ c`),
+		Entry("equivalence",
+			op(EquivOp, NewVar("a"), NewVar("b")),
+			`a === b`),
+	)
+	DescribeTable("ASCII layout", func(source string) { RoundTrip(source, printer.ASCII()) },
+		Entry("annotated merge", `(merge { A = 1 } < A >.A) : Natural`),
+		Entry("annotated toMap", `(toMap { a = 1 }) : List { mapKey : Text, mapValue : Natural }`),
+	)
+	DescribeTable("layout", RoundTrip,
+		Entry("short record", `{ a = 1, b = [ 1, 2 ] }`),
+		Entry("long record", `{ a = "aaaaaaaaaaaaaaaaaaaa"
+, b = "bbbbbbbbbbbbbbbbbbbb"
+, c = "cccccccccccccccccccc"
+, d = "dddddddddddddddddddd"
+}`),
+		Entry("let", `let x = 1
+
+let y : Natural = 2
+
+in  x + y`),
+		Entry("long application", `f
+  "aaaaaaaaaaaaaaaaaaaa"
+  "bbbbbbbbbbbbbbbbbbbb"
+  "cccccccccccccccccccc"
+  "dddddddddddddddddddd"`),
+		Entry("narrow lambda", `\(x : Natural) ->
+  \(y : Natural) ->
+    x + y`, printer.ASCII(), printer.Width(20)),
+		Entry("merge", `merge { A = 1 } < A >.A : Natural`),
+		Entry("annotated merge", `(merge { A = 1 } < A >.A) : Natural`),
+		Entry("completion", `(T::{ a = 1 }).a`),
+		Entry("with", `f (r with a.b = 1 with c = 2)`),
+		Entry("toMap", `toMap { a = 1 } : List { mapKey : Text, mapValue : Natural }`),
+		Entry("annotated toMap", `(toMap { a = 1 }) : List { mapKey : Text, mapValue : Natural }`),
+		Entry("projection", "r.{ Some, a }.(T)"),
+		Entry("remote import", `https://example.com/foo.dhall using (./headers.dhall) sha256:0000000000000000000000000000000000000000000000000000000000000000 as Text`),
+		Entry("if", `if True then 1 else 2`),
+		Entry("assert", `assert : 1 + 1 ≡ 2`),
+		Entry("nested let", `let a = 1 in let b = 2 in a + b`),
+		Entry("let in operand", `(let a = 1 in a) + 1`),
+	)
+	DescribeTable("comments", func(source, expected string) {
+		t, err := parser.Parse("test", []byte(source), parser.WithComments())
+		Expect(err).ToNot(HaveOccurred())
+		PrintAndCompare(t, expected)
+	},
+		Entry("header", "-- a\n{- b -}\n1", "-- a\n{- b -}\n1"),
+		Entry("footer", "1\n-- a\n", "1\n-- a"),
+		Entry("end of line", "1 -- a\n-- b\n", "1 -- a\n-- b"),
+		Entry("multi-line block comment", "{- a\n     b -}\n1", "{- a\n     b -}\n1"),
+		Entry("before record field", "{ a = 1,\n -- b\n b = 2 }", `{ a = 1
+, -- b
+  b = 2
+}`),
+		Entry("before let binding value", "let x = 1 let y = -- y\n 2 in x", `let x = 1
+
+-- y
+let y = 2
+
+in  x`),
+		Entry("before operand", "1 + -- a\n 2", "1\n+ -- a\n  2"),
+		Entry("after record fields", "{ a = 1 -- the a field\n, b = 2 -- the b field\n}", `{ a = 1 -- the a field
+, b = 2 -- the b field
+}`),
+		Entry("after list elements", "[ 1 -- one\n, 2 {- two -}\n]", `[ 1 -- one
+, 2 {- two -}
+]`),
+		Entry("after let binding values", "let x = 1 -- one\nlet y = 2 -- two\nin x + y", `let x = 1 -- one
+
+let y = 2 -- two
+
+in  x + y`),
+		Entry("after a single let binding", "let x = 1 -- one\nin x", `let x = 1 -- one
+
+in  x`),
+		Entry("after a separator", "[ 1, -- one\n  2 ]", `[ 1 -- one
+, 2
+]`),
+		Entry("unsorted record fields", "{ z = a, y = b, x = c, w = d }", "{ z = a, y = b, x = c, w = d }"),
+		Entry("unsorted record type fields", "{ z : A, y : B }", "{ z : A, y : B }"),
+		Entry("unsorted union alternatives", "< Z | Y : Natural | X >", "< Z | Y : Natural | X >"),
+		Entry("puns", "{ y, x = 1, w }", "{ y, x = 1, w }"),
+		Entry("after a parenthesized operand", "(1 + 2 -- a\n) * 3", "(1 + 2 -- a\n)\n* 3"),
+	)
+	It("fails on a LocalVar", func() {
+		_, err := printer.Sprint(NewLambda("x", Natural, LocalVar{Name: "x"}))
+		Expect(err).To(MatchError(ContainSubstring("can't print Dhall")))
+	})
+	It("writes with Fprint", func() {
+		var buf bytes.Buffer
+		Expect(printer.Fprint(&buf, NaturalLit(1))).To(Succeed())
+		Expect(buf.String()).To(Equal("1"))
+	})
+})
@@ -1,7 +1,6 @@
 package dhall_test
 
 import (
-	"fmt"
 	"math"
 	"reflect"
 	"testing"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/wallyqs/dhall.go/core"
 	"github.com/wallyqs/dhall.go/parser"
+	"github.com/wallyqs/dhall.go/printer"
 	"github.com/wallyqs/dhall.go/term"
 )
 
@@ -78,13 +78,16 @@ func TestParseWhatYouWrite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping slow test in short mode")
 	}
-	t.Skip("TODO: This is broken on the nbe branch until we reproduce proper printing")
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("written expressions parse back as themselves",
 		prop.ForAll(
 			func(e term.Term) bool {
-				expr, err := parser.Parse("-", []byte(fmt.Sprint(e)))
+				source, err := printer.Sprint(e)
+				if err != nil {
+					return false
+				}
+				expr, err := parser.Parse("-", []byte(source))
 				if err != nil {
 					return false
 				}
@@ -4,7 +4,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
-	. "github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/core"
 	"github.com/wallyqs/dhall.go/parser"
 )
 
@@ -13,7 +13,7 @@ func parseAndTypecheckTest(source string, expectedTypeSource string) {
 	Ω(err).ShouldNot(HaveOccurred())
 	parsedType, err := parser.Parse("-", []byte(expectedTypeSource))
 	Ω(err).ShouldNot(HaveOccurred())
-	Ω(TypeOf(parsed)).Should(BeAlphaEquivalentTo(parsedType))
+	Ω(core.TypeOf(parsed)).Should(core.BeAlphaEquivalentTo(parsedType))
 }
 
 var _ = Describe("Regression tests", func() {
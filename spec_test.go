@@ -26,24 +26,6 @@ var slowTests = []string{
 }
 
 var expectedFailures = []string{
-	// needs `using`
-	"TestParserAccepts/unit/import/Headers",
-	"TestParserAccepts/unit/import/inlineUsing",
-	"TestParserAccepts/unit/import/parenthesizeUsing",
-	"TestParserAccepts/usingToMap",
-	"TestTypeInferenceFails/customHeadersUsingBoundVariable",
-	"TestImport/customHeadersA.dhall",
-	"TestImport/headerForwardingA.dhall",
-	"TestImport/noHeaderForwardingA.dhall",
-	"TestImportFails/customHeadersUsingBoundVariable",
-
-	// needs bigint support
-	"TestNormalization/simple/integerToDoubleA.dhall",
-	"TestSemanticHash/simple/integerToDouble",
-	"TestBinaryDecode/unit/IntegerBigNegative",
-	"TestBinaryDecode/unit/IntegerBigPositive",
-	"TestBinaryDecode/unit/NaturalBig",
-
 	// urgh IEEE floating point; NaN != NaN :(
 	"TestTypeInference/unit/AssertNaNA",
 
@@ -59,9 +41,6 @@ var expectedFailures = []string{
 	// We respect RFC3986, but the dhall standard does not
 	"TestImport/unit/asLocation/RemoteCanonicalize4",
 
-	// We don't cache the same URL within the same run
-	"TestTypeInference/CacheImports",
-
 	// We alpha-normalize due to the enforced caching in the prelude
 	// import
 	"TestNormalization/remoteSystems",
@@ -459,3 +438,106 @@ func TestBinaryDecodeFails(t *testing.T) {
 		expectError(t, err)
 	})
 }
+
+// TestShowConstructor covers `showConstructor`, which postdates the
+// version of dhall-lang that our test suite is pinned to.  The cases
+// mirror the ShowConstructor unit tests from the standard.
+func TestShowConstructor(t *testing.T) {
+	t.Parallel()
+	normalization := map[string]struct{ a, b string }{
+		"EmptyAlternative": {`showConstructor < A | B : Natural >.A`, `"A"`},
+		"Alternative":      {`showConstructor (< A | B : Natural >.B 1)`, `"B"`},
+		"Some":             {`showConstructor (Some 1)`, `"Some"`},
+		"None":             {`showConstructor (None Natural)`, `"None"`},
+		"Neutral":          {`λ(x : < A | B >) → showConstructor x`, `λ(x : < A | B >) → showConstructor x`},
+	}
+	for name, test := range normalization {
+		test := test
+		t.Run("Normalization/"+name, func(t *testing.T) {
+			parsedA, err := parser.Parse("-", []byte(test.a))
+			expectNoError(t, err)
+			parsedB, err := parser.Parse("-", []byte(test.b))
+			expectNoError(t, err)
+
+			_, err = core.TypeOf(parsedA)
+			expectNoError(t, err)
+
+			expectEqualTerms(t, parsedB, core.Quote(core.Eval(parsedA)))
+			pass(t)
+		})
+	}
+	failures := map[string]string{
+		"NotUnion":  `showConstructor 1`,
+		"Record":    `showConstructor { x = 1 }`,
+		"UnionType": `showConstructor < A | B >`,
+		"Bare":      `showConstructor`,
+	}
+	for name, source := range failures {
+		source := source
+		t.Run("TypeInferenceFails/"+name, func(t *testing.T) {
+			parsed, err := parser.Parse("-", []byte(source))
+			if err == nil {
+				_, err = core.TypeOf(parsed)
+			}
+			expectError(t, err)
+			pass(t)
+		})
+	}
+	t.Run("Binary", func(t *testing.T) {
+		encoded := []byte{0x82, 0x18, 0x22, 0x82, 0x61, 0x78, 0x00}
+		parsed, err := parser.Parse("-", []byte(`showConstructor x`))
+		expectNoError(t, err)
+
+		actualBuf := new(bytes.Buffer)
+		err = binary.EncodeAsCbor(actualBuf, parsed)
+		expectNoError(t, err)
+		expectEqualBytes(t, encoded, actualBuf.Bytes())
+
+		decoded, err := binary.DecodeAsCbor(bytes.NewReader(encoded))
+		expectNoError(t, err)
+		expectEqualTerms(t, parsed, decoded)
+		pass(t)
+	})
+}
+
+func TestBigNumbers(t *testing.T) {
+	t.Parallel()
+	ff := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	twoTo64 := []byte{0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}
+	binaryTests := map[string]struct {
+		source  string
+		encoded []byte
+	}{
+		"NaturalMaxUint64": {`18446744073709551615`, append([]byte{0x82, 0x0f, 0x1b}, ff...)},
+		"NaturalBig":       {`18446744073709551616`, append([]byte{0x82, 0x0f, 0xc2}, twoTo64...)},
+		"IntegerMaxInt64Plus1": {`+9223372036854775808`,
+			[]byte{0x82, 0x10, 0x1b, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		"IntegerMinInt64Minus1": {`-9223372036854775809`,
+			[]byte{0x82, 0x10, 0x3b, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		"IntegerNegative2To64": {`-18446744073709551616`, append([]byte{0x82, 0x10, 0x3b}, ff...)},
+		"IntegerBigPositive":   {`+18446744073709551616`, append([]byte{0x82, 0x10, 0xc2}, twoTo64...)},
+		"IntegerBigNegative":   {`-18446744073709551617`, append([]byte{0x82, 0x10, 0xc3}, twoTo64...)},
+	}
+	for name, test := range binaryTests {
+		test := test
+		t.Run("Binary/"+name, func(t *testing.T) {
+			parsed, err := parser.Parse("-", []byte(test.source))
+			expectNoError(t, err)
+
+			actualBuf := new(bytes.Buffer)
+			err = binary.EncodeAsCbor(actualBuf, parsed)
+			expectNoError(t, err)
+			expectEqualBytes(t, test.encoded, actualBuf.Bytes())
+
+			decoded, err := binary.DecodeAsCbor(bytes.NewReader(test.encoded))
+			expectNoError(t, err)
+			expectEqualTerms(t, parsed, decoded)
+			pass(t)
+		})
+	}
+	t.Run("BinaryDecode/NegativeNatural", func(t *testing.T) {
+		_, err := binary.DecodeAsCbor(bytes.NewReader([]byte{0x82, 0x0f, 0x20}))
+		expectError(t, err)
+		pass(t)
+	})
+}
@@ -0,0 +1,176 @@
+package dhall
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/term"
+)
+
+// A Date is a calendar date with no time or time zone, in the style
+// of cloud.google.com/go/civil.  A Dhall Date decodes into a Date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// A TimeOfDay is a wall-clock time with no date or time zone, in the
+// style of cloud.google.com/go/civil.  A Dhall Time decodes into a
+// TimeOfDay.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+func (t TimeOfDay) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond == 0 {
+		return s
+	}
+	return s + strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	dateType      = reflect.TypeOf(Date{})
+	timeOfDayType = reflect.TypeOf(TimeOfDay{})
+	locationType  = reflect.TypeOf(&time.Location{})
+)
+
+func dateOf(d core.DateLit) Date {
+	return Date{Year: d.Year, Month: time.Month(d.Month), Day: d.Day}
+}
+
+// nanoseconds converts the fractional digits of a Time literal to
+// nanoseconds, truncating any precision beyond nanoseconds.
+func nanoseconds(fraction string) int {
+	digits := []byte("000000000")
+	copy(digits, fraction)
+	n := 0
+	for _, d := range digits {
+		n = n*10 + int(d-'0')
+	}
+	return n
+}
+
+func timeOfDayOf(t core.TimeLit) TimeOfDay {
+	return TimeOfDay{
+		Hour:       t.Hour,
+		Minute:     t.Minute,
+		Second:     t.Second,
+		Nanosecond: nanoseconds(t.Fraction),
+	}
+}
+
+func locationOf(z core.TimeZoneLit) *time.Location {
+	if z == 0 {
+		return time.UTC
+	}
+	return time.FixedZone(term.TimeZoneLit(z).String(), int(z)*60)
+}
+
+// decodeTemporal decodes Date, Time and TimeZone literals, and
+// records of them, into Go's time types.  It reports whether it
+// handled the decoding at all.
+func decodeTemporal(e core.Value, v reflect.Value) (bool, error) {
+	switch e := e.(type) {
+	case core.DateLit:
+		switch v.Type() {
+		case dateType:
+			v.Set(reflect.ValueOf(dateOf(e)))
+			return true, nil
+		case timeType:
+			v.Set(reflect.ValueOf(time.Date(e.Year, time.Month(e.Month), e.Day, 0, 0, 0, 0, time.UTC)))
+			return true, nil
+		}
+		if v.Kind() == reflect.Interface {
+			v.Set(reflect.ValueOf(dateOf(e)))
+			return true, nil
+		}
+	case core.TimeLit:
+		if v.Type() == timeOfDayType || v.Kind() == reflect.Interface {
+			v.Set(reflect.ValueOf(timeOfDayOf(e)))
+			return true, nil
+		}
+	case core.TimeZoneLit:
+		if v.Type() == locationType || v.Kind() == reflect.Interface {
+			v.Set(reflect.ValueOf(locationOf(e)))
+			return true, nil
+		}
+	case core.RecordLit:
+		if v.Type() != timeType {
+			return false, nil
+		}
+		date, ok := e["date"].(core.DateLit)
+		if !ok {
+			return true, fmt.Errorf("Can't decode %v into time.Time without a date field", e)
+		}
+		var t TimeOfDay
+		if timeLit, ok := e["time"].(core.TimeLit); ok {
+			t = timeOfDayOf(timeLit)
+		}
+		loc := time.UTC
+		if zone, ok := e["timeZone"].(core.TimeZoneLit); ok {
+			loc = locationOf(zone)
+		}
+		v.Set(reflect.ValueOf(time.Date(
+			date.Year, time.Month(date.Month), date.Day,
+			t.Hour, t.Minute, t.Second, t.Nanosecond, loc)))
+		return true, nil
+	}
+	return false, nil
+}
+
+// encodeTemporal is the inverse of decodeTemporal.  It reports
+// whether it handled the encoding at all.  A *time.Location is only
+// encoded if its offset from UTC is fixed for all time, as that of
+// time.FixedZone is, since a TimeZone literal is a single offset.
+func encodeTemporal(val reflect.Value, typ core.Value) (core.Value, bool, error) {
+	if !val.IsValid() || !val.CanInterface() {
+		return nil, false, nil
+	}
+	switch typ {
+	case core.Date:
+		switch d := val.Interface().(type) {
+		case Date:
+			return core.DateLit{Year: d.Year, Month: int(d.Month), Day: d.Day}, true, nil
+		case time.Time:
+			return core.DateLit{Year: d.Year(), Month: int(d.Month()), Day: d.Day()}, true, nil
+		}
+	case core.Time:
+		var t TimeOfDay
+		switch v := val.Interface().(type) {
+		case TimeOfDay:
+			t = v
+		case time.Time:
+			t = TimeOfDay{v.Hour(), v.Minute(), v.Second(), v.Nanosecond()}
+		default:
+			return nil, false, nil
+		}
+		fraction := ""
+		if t.Nanosecond != 0 {
+			fraction = strings.TrimRight(fmt.Sprintf("%09d", t.Nanosecond), "0")
+		}
+		return core.TimeLit{Hour: t.Hour, Minute: t.Minute, Second: t.Second, Fraction: fraction}, true, nil
+	case core.TimeZone:
+		if loc, ok := val.Interface().(*time.Location); ok && loc != nil {
+			now := time.Now().In(loc)
+			if start, end := now.ZoneBounds(); !start.IsZero() || !end.IsZero() {
+				return nil, true, fmt.Errorf("Can't encode %v as %v: its offset from UTC is not fixed", loc, typ)
+			}
+			_, offset := now.Zone()
+			return core.TimeZoneLit(offset / 60), true, nil
+		}
+	}
+	return nil, false, nil
+}
@@ -2,7 +2,9 @@ package term
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math/big"
 	"sort"
 
 	"github.com/fxamacker/cbor/v2"
@@ -39,6 +41,12 @@ func (v Var) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal([]interface{}{v.Name, v.Index})
 }
 
+// MarshalCBOR implements cbor.Marshaler.  Notes are not part of
+// the binary encoding, so this encodes n.Term without them.
+func (n Note) MarshalCBOR() ([]byte, error) {
+	return em.Marshal(StripNotes(n.Term))
+}
+
 // MarshalCBOR implements cbor.Marshaler.
 func (app App) MarshalCBOR() ([]byte, error) {
 	fn := app.Fn
@@ -210,11 +218,45 @@ func (n NaturalLit) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal([]interface{}{15, uint(n)})
 }
 
+// MarshalCBOR implements cbor.Marshaler.
+func (n BigNaturalLit) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{15, bigIntCBOR(n.Int)})
+}
+
 // MarshalCBOR implements cbor.Marshaler.
 func (i IntegerLit) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal([]interface{}{16, int(i)})
 }
 
+// MarshalCBOR implements cbor.Marshaler.
+func (i BigIntegerLit) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{16, bigIntCBOR(i.Int)})
+}
+
+// bigIntCBOR returns a value which encodes i in the smallest CBOR
+// representation: a major type 0 or 1 integer if it fits in 64
+// bits, and a bignum (tag 2 or 3) otherwise.
+func bigIntCBOR(i *big.Int) interface{} {
+	if i.IsUint64() {
+		return i.Uint64()
+	}
+	if i.IsInt64() {
+		return i.Int64()
+	}
+	if i.Sign() < 0 {
+		// CBOR encodes a negative integer n as -1-n
+		n := new(big.Int).Not(i)
+		if n.IsUint64() {
+			head := make([]byte, 9)
+			head[0] = 0x3b // major type 1, 8-byte argument
+			binary.BigEndian.PutUint64(head[1:], n.Uint64())
+			return cbor.RawMessage(head)
+		}
+		return cbor.Tag{Number: 3, Content: n.Bytes()}
+	}
+	return cbor.Tag{Number: 2, Content: i.Bytes()}
+}
+
 // MarshalCBOR implements cbor.Marshaler.
 func (t TextLit) MarshalCBOR() ([]byte, error) {
 	output := []interface{}{18}
@@ -225,6 +267,47 @@ func (t TextLit) MarshalCBOR() ([]byte, error) {
 	return em.Marshal(output)
 }
 
+// MarshalCBOR implements cbor.Marshaler.
+func (s ShowConstructor) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{34, s.Expr})
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (d DateLit) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{30, d.Year, d.Month, d.Day})
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (t TimeLit) MarshalCBOR() ([]byte, error) {
+	mantissa, ok := new(big.Int).SetString(fmt.Sprintf("%d%s", t.Second, t.Fraction), 10)
+	if !ok {
+		return nil, fmt.Errorf("Invalid Time fraction %q", t.Fraction)
+	}
+	var m interface{}
+	if mantissa.IsUint64() {
+		m = mantissa.Uint64()
+	} else {
+		m = cbor.Tag{Number: 2, Content: mantissa.Bytes()}
+	}
+	seconds := cbor.Tag{Number: 4, Content: []interface{}{-len(t.Fraction), m}}
+	return em.Marshal([]interface{}{31, t.Hour, t.Minute, seconds})
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (z TimeZoneLit) MarshalCBOR() ([]byte, error) {
+	minutes := int(z)
+	positive := minutes >= 0
+	if !positive {
+		minutes = -minutes
+	}
+	return em.Marshal([]interface{}{32, positive, minutes / 60, minutes % 60})
+}
+
+// MarshalCBOR implements cbor.Marshaler.
+func (b BytesLit) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{33, []byte(b)})
+}
+
 // MarshalCBOR implements cbor.Marshaler.
 func (a Assert) MarshalCBOR() ([]byte, error) {
 	return em.Marshal([]interface{}{19, a.Annotation})
@@ -282,7 +365,10 @@ func (i Import) MarshalCBOR() ([]byte, error) {
 		}
 		return cbor.Marshal(toEncode)
 	case RemoteFile:
-		var headers interface{} // unimplemented, leave as nil for now
+		var headers interface{}
+		if rr.Headers() != nil {
+			headers = rr.Headers()
+		}
 		scheme := httpsImport
 		if rr.IsPlainHTTP() {
 			scheme = httpImport
@@ -22,8 +22,12 @@ type EnvVar string
 type LocalFile string
 
 // A RemoteFile is a Fetchable which represents fetching the content
-// of a remote file (over HTTP or HTTPS).
-type RemoteFile struct{ url *url.URL }
+// of a remote file (over HTTP or HTTPS).  It may carry a headers
+// expression from a `using` clause, which is sent with the request.
+type RemoteFile struct {
+	url     *url.URL
+	headers Term // nil if there is no `using` clause
+}
 
 // Missing is a Fetchable which cannot be Fetched.
 type Missing struct{}
@@ -51,10 +55,40 @@ type Fetchable interface {
 	AsLocation() Term
 }
 
-var _ Fetchable = EnvVar("")
-var _ Fetchable = LocalFile("")
-var _ Fetchable = RemoteFile{}
-var _ Fetchable = Missing{}
+// A FetchableWith is a Fetchable which can fetch its resource with a
+// given Fetcher.  All the Fetchables in this package are
+// FetchableWiths; an imports.Loader fetches other Fetchables with
+// Fetch(), bypassing its Fetcher.
+type FetchableWith interface {
+	Fetchable
+	FetchWith(origin string, f Fetcher) (string, error)
+}
+
+// A Fetcher provides access to the underlying resources which
+// Fetchables fetch: local files, environment variables and HTTP
+// servers.  Fetch() uses OSFetcher; FetchWith() lets callers supply
+// their own Fetcher, for example to read local files from an fs.FS.
+type Fetcher interface {
+	ReadFile(name string) ([]byte, error)
+	LookupEnv(name string) (string, bool)
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// OSFetcher is a Fetcher which reads the local filesystem and
+// environment of the running process, and makes HTTP requests with a
+// default http.Client.
+var OSFetcher Fetcher = osFetcher{}
+
+type osFetcher struct{}
+
+func (osFetcher) ReadFile(name string) ([]byte, error)         { return ioutil.ReadFile(name) }
+func (osFetcher) LookupEnv(name string) (string, bool)         { return os.LookupEnv(name) }
+func (osFetcher) Do(req *http.Request) (*http.Response, error) { return client.Do(req) }
+
+var _ FetchableWith = EnvVar("")
+var _ FetchableWith = LocalFile("")
+var _ FetchableWith = RemoteFile{}
+var _ FetchableWith = Missing{}
 
 // Origin returns NullOrigin, since EnvVars do not have an origin.
 func (EnvVar) Origin() string { return NullOrigin }
@@ -66,10 +100,16 @@ func (e EnvVar) String() string {
 // an error is returned, to prevent remote imports from importing
 // environment variables.
 func (e EnvVar) Fetch(origin string) (string, error) {
+	return e.FetchWith(origin, OSFetcher)
+}
+
+// FetchWith is like Fetch, but looks up the environment variable
+// with f.
+func (e EnvVar) FetchWith(origin string, f Fetcher) (string, error) {
 	if origin != NullOrigin {
 		return "", errors.New("Can't access environment variable from remote import")
 	}
-	val, ok := os.LookupEnv(string(e))
+	val, ok := f.LookupEnv(string(e))
 	if !ok {
 		return "", fmt.Errorf("Unset environment variable %s", string(e))
 	}
@@ -99,10 +139,15 @@ func (l LocalFile) String() string {
 // Fetch reads the local file.  If origin is not NullOrigin, an error
 // is returned, to prevent remote imports from importing local files.
 func (l LocalFile) Fetch(origin string) (string, error) {
+	return l.FetchWith(origin, OSFetcher)
+}
+
+// FetchWith is like Fetch, but reads the file with f.
+func (l LocalFile) FetchWith(origin string, f Fetcher) (string, error) {
 	if origin != NullOrigin {
 		return "", fmt.Errorf("Can't get %s from remote import at %s", l, origin)
 	}
-	bytes, err := ioutil.ReadFile(string(l))
+	bytes, err := f.ReadFile(string(l))
 	return string(bytes), err
 }
 
@@ -133,7 +178,8 @@ func (l LocalFile) ChainOnto(base Fetchable) (Fetchable, error) {
 			return nil, errors.New("Can't get home-relative path from remote import")
 		}
 		newURL := r.url.ResolveReference(l.asRelativeRef())
-		return RemoteFile{url: newURL}, nil
+		// relative imports inherit the headers of their parent
+		return RemoteFile{url: newURL, headers: r.headers}, nil
 	default:
 		return l, nil
 	}
@@ -195,6 +241,25 @@ func NewRemoteFile(u *url.URL) RemoteFile {
 	return RemoteFile{url: canonicalized}
 }
 
+// NewRemoteFileUsing constructs a RemoteFile from a *url.URL and
+// the headers expression of a `using` clause.
+func NewRemoteFileUsing(u *url.URL, headers Term) RemoteFile {
+	r := NewRemoteFile(u)
+	r.headers = headers
+	return r
+}
+
+// Headers returns the headers expression of the RemoteFile's `using`
+// clause, or nil if it has none.
+func (r RemoteFile) Headers() Term { return r.headers }
+
+// WithHeaders returns a copy of the RemoteFile with its headers
+// expression replaced by headers.
+func (r RemoteFile) WithHeaders(headers Term) RemoteFile {
+	r.headers = headers
+	return r
+}
+
 var client http.Client
 
 // Origin returns the scheme and authority of the underlying URL of a
@@ -208,17 +273,27 @@ func (r RemoteFile) String() string { return fmt.Sprintf("%v", r.url) }
 // considered a cross-origin request and so appropriate CORS checks
 // are made; if these fail, an error is returned with no content.
 func (r RemoteFile) Fetch(origin string) (string, error) {
+	return r.FetchWith(origin, OSFetcher)
+}
+
+// FetchWith is like Fetch, but makes the HTTP request with f.
+func (r RemoteFile) FetchWith(origin string, f Fetcher) (string, error) {
 	req, err := http.NewRequest("GET", r.url.String(), nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("User-Agent", "dhall-golang")
+	if r.headers != nil {
+		if err = addHeaders(req.Header, r.headers); err != nil {
+			return "", err
+		}
+	}
 	corsFlag := origin != NullOrigin && origin != r.Origin()
 	if corsFlag {
 		req.Header.Set("Origin", origin)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := f.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -236,6 +311,31 @@ func (r RemoteFile) Fetch(origin string) (string, error) {
 	return string(bodyBytes), err
 }
 
+// addHeaders adds the headers in the resolved and normalized headers
+// expression of a `using` clause to h.
+func addHeaders(h http.Header, headers Term) error {
+	if _, ok := headers.(EmptyList); ok {
+		return nil
+	}
+	list, ok := headers.(NonEmptyList)
+	if !ok {
+		return fmt.Errorf("Can't send unresolved headers %v", headers)
+	}
+	for _, entry := range list {
+		record, ok := entry.(RecordLit)
+		if !ok {
+			return fmt.Errorf("Can't send unresolved header %v", entry)
+		}
+		key, keyOk := record["mapKey"].(TextLit)
+		value, valueOk := record["mapValue"].(TextLit)
+		if !keyOk || !valueOk || len(key.Chunks) != 0 || len(value.Chunks) != 0 {
+			return fmt.Errorf("Can't send unresolved header %v", entry)
+		}
+		h.Add(key.Suffix, value.Suffix)
+	}
+	return nil
+}
+
 // ChainOnto returns the RemoteFile unmodified.
 func (r RemoteFile) ChainOnto(base Fetchable) (Fetchable, error) {
 	return r, nil
@@ -288,6 +388,12 @@ func (Missing) Fetch(origin string) (string, error) {
 	return "", errors.New("Cannot resolve missing import")
 }
 
+// FetchWith always returns an error, because Missing cannot be
+// fetched.
+func (m Missing) FetchWith(origin string, f Fetcher) (string, error) {
+	return m.Fetch(origin)
+}
+
 // ChainOnto returns a Missing.
 func (Missing) ChainOnto(base Fetchable) (Fetchable, error) {
 	return Missing{}, nil
@@ -42,6 +42,12 @@ var _ = DescribeTable("ChainOnto", func(fetchable, base, expected Fetchable) {
 	Entry("Relative local onto EnvVar", LocalFile("foo"), EnvVar("bar"), LocalFile("foo")),
 	Entry("Relative local onto LocalFile", LocalFile("foo"), LocalFile("/bar/baz"), LocalFile("/bar/foo")),
 	Entry("Relative local onto RemoteFile", LocalFile("foo"), makeRemoteFile("https://example.com/bar/baz"), makeRemoteFile("https://example.com/bar/foo")),
+	Entry("Relative local onto RemoteFile with headers", LocalFile("foo"),
+		makeRemoteFile("https://example.com/bar/baz").WithHeaders(EmptyList{Type: Text}),
+		makeRemoteFile("https://example.com/bar/foo").WithHeaders(EmptyList{Type: Text})),
+	Entry("RemoteFile onto RemoteFile with headers", makeRemoteFile("https://example.org/foo"),
+		makeRemoteFile("https://example.com/bar/baz").WithHeaders(EmptyList{Type: Text}),
+		makeRemoteFile("https://example.org/foo")),
 	Entry("Relative local with tricky chars onto RemoteFile", LocalFile("foo:bar#[☃"), makeRemoteFile("https://example.com/bar/baz"), makeRemoteFile("https://example.com/bar/foo:bar%23%5B%E2%98%83")),
 	Entry("Relative local onto Missing", LocalFile("foo"), Missing{}, LocalFile("foo")),
 	Entry("Parent-relative local onto EnvVar", LocalFile("../foo"), EnvVar("bar"), LocalFile("../foo")),
@@ -0,0 +1,129 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Position is a point in Dhall source text.  Line and Column count
+// from 1, and Column counts runes; Offset counts bytes from 0.
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// A Span is the extent of the Dhall source text from which a Term was
+// parsed.
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+
+	// the full source text of File, for quoting in error messages
+	source string
+}
+
+// NewSpan returns the Span from start to end of source, which is the
+// text of the named file.
+func NewSpan(file, source string, start, end Position) Span {
+	return Span{File: file, Start: start, End: end, source: source}
+}
+
+// String returns the location of the start of s as "file:line:col".
+func (s Span) String() string {
+	return fmt.Sprintf("%s:%d:%d", s.File, s.Start.Line, s.Start.Column)
+}
+
+// Excerpt returns the line of source text on which s starts, followed
+// by a line which marks the span with a caret and tildes.  If the
+// source text of s is unknown, it returns "".
+func (s Span) Excerpt() string {
+	if s.source == "" || s.Start.Offset > len(s.source) {
+		return ""
+	}
+	lineStart := strings.LastIndexByte(s.source[:s.Start.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(s.source[s.Start.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(s.source)
+	} else {
+		lineEnd += s.Start.Offset
+	}
+	line := strings.TrimSuffix(s.source[lineStart:lineEnd], "\r")
+	var marker strings.Builder
+	// keep tabs, so that the marker lines up with the source
+	for _, r := range s.source[lineStart:s.Start.Offset] {
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	end := s.End.Offset
+	if end > lineStart+len(line) {
+		end = lineStart + len(line)
+	}
+	width := 1
+	if end > s.Start.Offset {
+		width = utf8.RuneCountInString(s.source[s.Start.Offset:end])
+	}
+	marker.WriteString("^")
+	marker.WriteString(strings.Repeat("~", width-1))
+	return line + "\n" + marker.String()
+}
+
+// A Note is a Term annotated with the Span of source text it was
+// parsed from.  The parser only produces Notes when asked to; Notes
+// have no meaning, and are discarded by evaluation.
+//
+// When asked, the parser also keeps the comments from the source text
+// in Notes, in Comments, which is nil if there are none, and the
+// Layout of each record and union, which is nil otherwise.  They are
+// kept behind pointers so that Notes, like other Terms, can be
+// compared with ==.
+type Note struct {
+	Span Span
+	Term Term
+
+	Comments *Comments
+	Layout   *Layout
+}
+
+// Comments are the comments around a Note's Term: Leading are those
+// which precede it, Line those which follow it at the end of the line
+// on which it ends, and Trailing those at the end of the source text,
+// after it.
+type Comments struct {
+	Leading  []string
+	Line     []string
+	Trailing []string
+}
+
+// A Layout records what a record or union Term doesn't about how it
+// was written: the order of its fields, and which fields of a record
+// literal were puns, like x in { x, y = 1 }.
+type Layout struct {
+	Fields []string
+	Puns   map[string]bool
+}
+
+func (Note) isTerm() {}
+
+func (n Note) String() string { return fmt.Sprint(n.Term) }
+
+// StripNotes returns t with all Notes removed.
+func StripNotes(t Term) Term {
+	switch t := t.(type) {
+	case Note:
+		return StripNotes(t.Term)
+	case Import:
+		// TransformSubexprs doesn't descend into the headers of a
+		// remote import
+		if remote, ok := t.Fetchable.(RemoteFile); ok && remote.Headers() != nil {
+			t.Fetchable = remote.WithHeaders(StripNotes(remote.Headers()))
+		}
+		return t
+	}
+	return TransformSubexprs(t, StripNotes)
+}
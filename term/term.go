@@ -10,6 +10,7 @@ package term
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"sort"
 	"strings"
 )
@@ -50,6 +51,10 @@ const (
 	List     Builtin = "List"
 	Optional Builtin = "Optional"
 	None     Builtin = "None"
+	Date     Builtin = "Date"
+	Time     Builtin = "Time"
+	TimeZone Builtin = "TimeZone"
+	Bytes    Builtin = "Bytes"
 
 	NaturalBuild     Builtin = "Natural/build"
 	NaturalFold      Builtin = "Natural/fold"
@@ -70,6 +75,12 @@ const (
 	TextShow    Builtin = "Text/show"
 	TextReplace Builtin = "Text/replace"
 
+	DateShow     Builtin = "Date/show"
+	TimeShow     Builtin = "Time/show"
+	TimeZoneShow Builtin = "TimeZone/show"
+
+	BytesShow Builtin = "Bytes/show"
+
 	ListBuild   Builtin = "List/build"
 	ListFold    Builtin = "List/fold"
 	ListLength  Builtin = "List/length"
@@ -283,6 +294,11 @@ type (
 	// A NaturalLit is a literal of type Natural.
 	NaturalLit uint
 
+	// A BigNaturalLit is a literal of type Natural which is too
+	// large to be represented as a NaturalLit.  Use NewNaturalLit()
+	// to construct Natural literals of arbitrary size.
+	BigNaturalLit struct{ *big.Int }
+
 	// An EmptyList is an empty list literal Term of the given type.
 	EmptyList struct{ Type Term }
 
@@ -311,6 +327,35 @@ type (
 	// A IntegerLit is a literal of type Integer.
 	IntegerLit int
 
+	// A BigIntegerLit is a literal of type Integer which is too
+	// large to be represented as an IntegerLit.  Use NewIntegerLit()
+	// to construct Integer literals of arbitrary size.
+	BigIntegerLit struct{ *big.Int }
+
+	// A DateLit is a literal of type Date.
+	DateLit struct {
+		Year  int
+		Month int
+		Day   int
+	}
+
+	// A TimeLit is a literal of type Time.  Fraction holds the
+	// decimal digits after the seconds' decimal point (if any), so
+	// that the precision of the literal is preserved.
+	TimeLit struct {
+		Hour     int
+		Minute   int
+		Second   int
+		Fraction string
+	}
+
+	// A TimeZoneLit is a literal of type TimeZone.  It is stored as
+	// an offset from UTC in minutes.
+	TimeZoneLit int
+
+	// A BytesLit is a literal of type Bytes.
+	BytesLit []byte
+
 	// Some represents a Term which is present in an Optional type.
 	Some struct{ Val Term }
 
@@ -353,9 +398,34 @@ type (
 		Path   []string
 		Value  Term
 	}
+
+	// A ShowConstructor is a `showConstructor` expression, which
+	// gives the name of the alternative of a union or Optional
+	// value as Text.
+	ShowConstructor struct{ Expr Term }
 )
 
-func (NaturalLit) isTerm() {}
+func (NaturalLit) isTerm()    {}
+func (BigNaturalLit) isTerm() {}
+
+// NewNaturalLit returns a Natural literal Term for n, which must not
+// be negative.  It returns a NaturalLit if n is small enough, and a
+// BigNaturalLit otherwise.
+func NewNaturalLit(n *big.Int) Term {
+	if n.IsUint64() && n.Uint64() <= math.MaxUint {
+		return NaturalLit(n.Uint64())
+	}
+	return BigNaturalLit{n}
+}
+
+// NewIntegerLit returns an Integer literal Term for i.  It returns
+// an IntegerLit if i is small enough, and a BigIntegerLit otherwise.
+func NewIntegerLit(i *big.Int) Term {
+	if i.IsInt64() && i.Int64() >= math.MinInt && i.Int64() <= math.MaxInt {
+		return IntegerLit(i.Int64())
+	}
+	return BigIntegerLit{i}
+}
 
 func (EmptyList) isTerm() {}
 
@@ -416,8 +486,9 @@ func (t TextLit) String() string {
 
 func (If) isTerm() {}
 
-func (DoubleLit) isTerm()  {}
-func (IntegerLit) isTerm() {}
+func (DoubleLit) isTerm()     {}
+func (IntegerLit) isTerm()    {}
+func (BigIntegerLit) isTerm() {}
 
 func (d DoubleLit) String() string {
 	f := float64(d)
@@ -435,6 +506,32 @@ func (d DoubleLit) String() string {
 	return fmt.Sprintf("%#v", float64(d))
 }
 
+func (DateLit) isTerm()     {}
+func (TimeLit) isTerm()     {}
+func (TimeZoneLit) isTerm() {}
+func (BytesLit) isTerm()    {}
+
+func (d DateLit) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (t TimeLit) String() string {
+	if t.Fraction == "" {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%s", t.Hour, t.Minute, t.Second, t.Fraction)
+}
+
+func (z TimeZoneLit) String() string {
+	sign := '+'
+	minutes := int(z)
+	if minutes < 0 {
+		sign = '-'
+		minutes = -minutes
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, minutes/60, minutes%60)
+}
+
 func (Some) isTerm() {}
 
 func (RecordType) isTerm()  {}
@@ -448,6 +545,8 @@ func (Merge) isTerm()       {}
 func (Assert) isTerm()      {}
 func (With) isTerm()        {}
 
+func (ShowConstructor) isTerm() {}
+
 type (
 	// An Import is an import Term.
 	Import struct {
@@ -472,6 +571,7 @@ const (
 	Code     ImportMode = iota // Import as Dhall code.
 	RawText                    // Import as a Text value - `as Text`.
 	Location                   // Import as a Location - `as Location`.
+	RawBytes                   // Import as a Bytes value - `as Bytes`.
 )
 
 func (Import) isTerm() {}
@@ -3,14 +3,16 @@ package term
 import (
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // TransformSubexprs returns a Term with f() applied to each immediate
 // subexpression.
 func TransformSubexprs(t Term, f func(Term) Term) Term {
 	switch t := t.(type) {
-	case Universe, Builtin, Var, LocalVar, NaturalLit, DoubleLit, BoolLit,
-		IntegerLit:
+	case Universe, Builtin, Var, LocalVar, NaturalLit, BigNaturalLit,
+		DoubleLit, BoolLit, IntegerLit, BigIntegerLit,
+		DateLit, TimeLit, TimeZoneLit, BytesLit:
 		return t
 	case Lambda:
 		return Lambda{
@@ -144,8 +146,13 @@ func TransformSubexprs(t Term, f func(Term) Term) Term {
 			Path:   t.Path,
 			Value:  f(t.Value),
 		}
+	case ShowConstructor:
+		return ShowConstructor{Expr: f(t.Expr)}
 	case Import:
 		return t
+	case Note:
+		t.Term = f(t.Term)
+		return t
 	default:
 		panic(fmt.Sprintf("unknown term type %+v (%v)", t, reflect.ValueOf(t).Type()))
 	}
@@ -153,11 +160,14 @@ func TransformSubexprs(t Term, f func(Term) Term) Term {
 
 // MaybeTransformSubexprs returns a Term with f() applied to each
 // immediate subexpression.  If f() returns an error at any point,
-// MaybeTransformSubexprs returns that error.
+// MaybeTransformSubexprs returns that error.  Fields of records and
+// unions are visited in order of their labels, so that f() is
+// applied in a deterministic order.
 func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 	switch t := t.(type) {
-	case Universe, Builtin, Var, LocalVar, NaturalLit, DoubleLit, BoolLit,
-		IntegerLit:
+	case Universe, Builtin, Var, LocalVar, NaturalLit, BigNaturalLit,
+		DoubleLit, BoolLit, IntegerLit, BigIntegerLit,
+		DateLit, TimeLit, TimeZoneLit, BytesLit:
 		return t, nil
 	case Lambda:
 		typ, err := f(t.Type)
@@ -278,9 +288,9 @@ func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 		return Some{val}, err
 	case RecordType:
 		result := make(RecordType, len(t))
-		for k, v := range t {
+		for _, k := range sortedLabels(t) {
 			var err error
-			result[k], err = f(v)
+			result[k], err = f(t[k])
 			if err != nil {
 				return nil, err
 			}
@@ -288,9 +298,9 @@ func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 		return result, nil
 	case RecordLit:
 		result := make(RecordLit, len(t))
-		for k, v := range t {
+		for _, k := range sortedLabels(t) {
 			var err error
-			result[k], err = f(v)
+			result[k], err = f(t[k])
 			if err != nil {
 				return nil, err
 			}
@@ -330,7 +340,8 @@ func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 		return ProjectType{Record: record, Selector: selector}, nil
 	case UnionType:
 		result := make(UnionType, len(t))
-		for k, v := range t {
+		for _, k := range sortedLabels(t) {
+			v := t[k]
 			if v == nil {
 				result[k] = nil
 				continue
@@ -376,8 +387,15 @@ func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 			Path:   t.Path,
 			Value:  value,
 		}, nil
+	case ShowConstructor:
+		expr, err := f(t.Expr)
+		return ShowConstructor{Expr: expr}, err
 	case Import:
 		return t, nil
+	case Note:
+		inner, err := f(t.Term)
+		t.Term = inner
+		return t, err
 	default:
 		if t == nil {
 			panic(fmt.Sprintf("nil term"))
@@ -385,3 +403,12 @@ func MaybeTransformSubexprs(t Term, f func(Term) (Term, error)) (Term, error) {
 		panic(fmt.Sprintf("unknown term type %+v (%v)", t, reflect.ValueOf(t).Type()))
 	}
 }
+
+func sortedLabels(fields map[string]Term) []string {
+	labels := make([]string, 0, len(fields))
+	for label := range fields {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
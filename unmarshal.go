@@ -1,9 +1,13 @@
 package dhall
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
+	"math/big"
 	"reflect"
 
 	"github.com/wallyqs/dhall.go/core"
@@ -106,45 +110,131 @@ func mkJSONType() core.Value {
 // imports, typechecks, evaluates, and unmarshals it into the given
 // variable.
 func Unmarshal(b []byte, out interface{}) error {
-	term, err := parser.Parse("-", b)
+	return UnmarshalContext(context.Background(), b, out)
+}
+
+// UnmarshalContext is like Unmarshal, but stops and returns ctx's
+// error if ctx is done before unmarshalling finishes.  ctx is also
+// used for any HTTP requests made to fetch remote imports.
+func UnmarshalContext(ctx context.Context, b []byte, out interface{}) error {
+	term, err := parser.Parse("-", b, parser.WithSpans())
 	if err != nil {
 		return err
 	}
-	return unmarshalTerm(term, out)
+	return unmarshalTerm(ctx, term, out)
+}
+
+// UnmarshalWithLimits is like UnmarshalContext, but also fails with
+// a *core.LimitError if typechecking or evaluation, including of
+// imported expressions, exceeds any of limits.  This is useful when
+// the input is untrusted.
+func UnmarshalWithLimits(ctx context.Context, b []byte, limits core.Limits, out interface{}) error {
+	t, err := parser.Parse("-", b, parser.WithSpans())
+	if err != nil {
+		return err
+	}
+	loader := imports.NewLoader(imports.WithLimits(limits))
+	resolved, err := loader.LoadContext(ctx, t)
+	if err != nil {
+		return err
+	}
+	return decodeResolved(ctx, resolved, limits, out)
 }
 
 // UnmarshalReader takes dhall input as a byte array and parses it, resolves
 // imports, typechecks, evaluates, and unmarshals it into the given
 // variable.
 func UnmarshalReader(filename string, r io.Reader, out interface{}) error {
-	term, err := parser.ParseReader(filename, r)
+	return UnmarshalReaderContext(context.Background(), filename, r, out)
+}
+
+// UnmarshalReaderContext is like UnmarshalReader, but stops and
+// returns ctx's error if ctx is done before unmarshalling finishes.
+func UnmarshalReaderContext(ctx context.Context, filename string, r io.Reader, out interface{}) error {
+	term, err := parser.ParseReader(filename, r, parser.WithSpans())
 	if err != nil {
 		return err
 	}
-	return unmarshalTerm(term, out)
+	return unmarshalTerm(ctx, term, out)
 }
 
 // UnmarshalFile takes dhall input from a file and parses it, resolves
 // imports, typechecks, evaluates, and unmarshals it into the given
 // variable.
 func UnmarshalFile(filename string, out interface{}) error {
-	term, err := parser.ParseFile(filename)
+	return UnmarshalFileContext(context.Background(), filename, out)
+}
+
+// UnmarshalFileContext is like UnmarshalFile, but stops and returns
+// ctx's error if ctx is done before unmarshalling finishes.
+func UnmarshalFileContext(ctx context.Context, filename string, out interface{}) error {
+	term, err := parser.ParseFile(filename, parser.WithSpans())
 	if err != nil {
 		return err
 	}
-	return unmarshalTerm(term, out)
+	return unmarshalTerm(ctx, term, out)
 }
 
-func unmarshalTerm(term term.Term, out interface{}) error {
-	resolved, err := imports.Load(term)
+// UnmarshalFileWithLoader is like UnmarshalFileContext, but resolves
+// imports with loader, so that the caller can choose its cache,
+// fetchers, policy or vendor directory.  Typechecking and evaluation
+// are subject to the loader's limits.
+func UnmarshalFileWithLoader(ctx context.Context, loader *imports.Loader, filename string, out interface{}) error {
+	t, err := parser.ParseFile(filename, parser.WithSpans())
 	if err != nil {
 		return err
 	}
-	_, err = core.TypeOf(resolved)
+	resolved, err := loader.LoadContext(ctx, t)
 	if err != nil {
 		return err
 	}
-	return Decode(core.Eval(resolved), out)
+	return decodeResolved(ctx, resolved, loader.Limits(), out)
+}
+
+// UnmarshalFS takes dhall input from the file name in fsys and parses
+// it, resolves imports, typechecks, evaluates, and unmarshals it into
+// the given variable.  Local imports are fetched from fsys, with
+// relative imports resolved relative to the importing file, so that
+// a tree of Dhall files can be loaded from an embed.FS.
+func UnmarshalFS(fsys fs.FS, name string, out interface{}) error {
+	return UnmarshalFSContext(context.Background(), fsys, name, out)
+}
+
+// UnmarshalFSContext is like UnmarshalFS, but stops and returns ctx's
+// error if ctx is done before unmarshalling finishes.
+func UnmarshalFSContext(ctx context.Context, fsys fs.FS, name string, out interface{}) error {
+	t, err := parser.ParseFS(fsys, name, parser.WithSpans())
+	if err != nil {
+		return err
+	}
+	loader := imports.NewLoader(imports.WithFS(fsys))
+	resolved, err := loader.LoadContext(ctx, t, term.LocalFile(name))
+	if err != nil {
+		return err
+	}
+	return decodeResolved(ctx, resolved, core.Limits{}, out)
+}
+
+func unmarshalTerm(ctx context.Context, term term.Term, out interface{}) error {
+	resolved, err := imports.LoadContext(ctx, term)
+	if err != nil {
+		return err
+	}
+	return decodeResolved(ctx, resolved, core.Limits{}, out)
+}
+
+// decodeResolved typechecks, evaluates and decodes a Term whose
+// imports have been resolved.
+func decodeResolved(ctx context.Context, resolved term.Term, limits core.Limits, out interface{}) error {
+	_, err := core.TypeOfWithLimits(ctx, resolved, limits)
+	if err != nil {
+		return err
+	}
+	val, err := core.EvalWithLimits(ctx, resolved, limits)
+	if err != nil {
+		return err
+	}
+	return Decode(val, out)
 }
 
 // Decode takes a core.Value and unmarshals it into the given
@@ -154,9 +244,38 @@ func Decode(e core.Value, out interface{}) error {
 	return decode(e, v.Elem())
 }
 
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// bigIntOf returns the value of a Natural or Integer literal as a
+// *big.Int.
+func bigIntOf(e core.Value) *big.Int {
+	switch e := e.(type) {
+	case core.NaturalLit:
+		return new(big.Int).SetUint64(uint64(e))
+	case core.BigNaturalLit:
+		return e.Int
+	case core.IntegerLit:
+		return big.NewInt(int64(e))
+	case core.BigIntegerLit:
+		return e.Int
+	}
+	return nil
+}
+
+func isNatural(e core.Value) bool {
+	switch e.(type) {
+	case core.NaturalLit, core.BigNaturalLit:
+		return true
+	}
+	return false
+}
+
 // encode converts a reflect.Value to a core.Value with the given
 // Dhall type
 func encode(val reflect.Value, typ core.Value) (core.Value, error) {
+	if temporal, ok, err := encodeTemporal(val, typ); ok {
+		return temporal, err
+	}
 	if opt, ok := typ.(core.OptionalOf); ok {
 		switch val.Kind() {
 		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
@@ -191,7 +310,12 @@ func encode(val reflect.Value, typ core.Value) (core.Value, error) {
 			return core.DoubleLit(val.Float()), nil
 		}
 		// no Complex32 or Complex64
-		// no Array
+	case reflect.Array:
+		if typ == core.Bytes && val.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, val.Len())
+			reflect.Copy(reflect.ValueOf(b), val)
+			return core.BytesLit(b), nil
+		}
 		// no Chan
 	case reflect.Func: // not implemented
 		// no Interface
@@ -232,6 +356,9 @@ func encode(val reflect.Value, typ core.Value) (core.Value, error) {
 	case reflect.Ptr:
 		return encode(val.Elem(), typ)
 	case reflect.Slice:
+		if typ == core.Bytes && val.Type().Elem().Kind() == reflect.Uint8 {
+			return core.BytesLit(append([]byte{}, val.Bytes()...)), nil
+		}
 		e, ok := typ.(core.ListOf)
 		if !ok {
 			break
@@ -250,6 +377,16 @@ func encode(val reflect.Value, typ core.Value) (core.Value, error) {
 			return core.PlainTextLit(val.String()), nil
 		}
 	case reflect.Struct:
+		if val.Type() == bigIntType {
+			n := val.Interface().(big.Int)
+			switch {
+			case typ == core.Integer:
+				return core.NewIntegerLit(new(big.Int).Set(&n)), nil
+			case typ == core.Natural && n.Sign() >= 0:
+				return core.NewNaturalLit(new(big.Int).Set(&n)), nil
+			}
+			break
+		}
 		e, ok := typ.(core.RecordType)
 		if !ok {
 			break
@@ -345,6 +482,9 @@ func decode(e core.Value, v reflect.Value) error {
 			}
 		}
 	}
+	if ok, err := decodeTemporal(e, v); ok {
+		return err
+	}
 	if v.Kind() == reflect.Ptr {
 		v.Set(reflect.New(v.Type().Elem()))
 		return decode(e, v.Elem())
@@ -360,28 +500,37 @@ types:
 			v.Set(reflect.ValueOf(bool(e)))
 			return nil
 		}
-	case core.NaturalLit:
+	case core.NaturalLit, core.BigNaturalLit, core.IntegerLit, core.BigIntegerLit:
+		n := bigIntOf(e)
 		switch v.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16,
 			reflect.Int32, reflect.Int64:
-			v.SetInt(int64(e))
+			if !n.IsInt64() || v.OverflowInt(n.Int64()) {
+				return fmt.Errorf("Dhall value %v overflows %v", n, v.Type())
+			}
+			v.SetInt(n.Int64())
 			return nil
 		case reflect.Uint, reflect.Uint8, reflect.Uint16,
 			reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			v.SetUint(uint64(e))
-			return nil
-		case reflect.Interface:
-			v.Set(reflect.ValueOf(int(e)))
-			return nil
-		}
-	case core.IntegerLit:
-		switch v.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16,
-			reflect.Int32, reflect.Int64:
-			v.SetInt(int64(e))
+			if !isNatural(e) {
+				break
+			}
+			if !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+				return fmt.Errorf("Dhall value %v overflows %v", n, v.Type())
+			}
+			v.SetUint(n.Uint64())
 			return nil
+		case reflect.Struct:
+			if v.Type() == bigIntType {
+				v.Addr().Interface().(*big.Int).Set(n)
+				return nil
+			}
 		case reflect.Interface:
-			v.Set(reflect.ValueOf(int(e)))
+			if n.IsInt64() && n.Int64() >= math.MinInt && n.Int64() <= math.MaxInt {
+				v.Set(reflect.ValueOf(int(n.Int64())))
+			} else {
+				v.Set(reflect.ValueOf(new(big.Int).Set(n)))
+			}
 			return nil
 		}
 	case core.DoubleLit:
@@ -402,6 +551,25 @@ types:
 			v.Set(reflect.ValueOf(string(e)))
 			return nil
 		}
+	case core.BytesLit:
+		switch v.Kind() {
+		case reflect.Slice:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				v.SetBytes(append([]byte{}, e...))
+				return nil
+			}
+		case reflect.Array:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				if v.Len() != len(e) {
+					return fmt.Errorf("Can't decode %d bytes into %v", len(e), v.Type())
+				}
+				reflect.Copy(v, reflect.ValueOf([]byte(e)))
+				return nil
+			}
+		case reflect.Interface:
+			v.Set(reflect.ValueOf(append([]byte{}, e...)))
+			return nil
+		}
 	case core.EmptyList:
 		switch v.Kind() {
 		case reflect.Slice:
@@ -1,10 +1,16 @@
 package dhall_test
 
 import (
+	"context"
+	"math"
+	"math/big"
 	"reflect"
+	"testing/fstest"
+	"time"
 
 	. "github.com/wallyqs/dhall.go"
 	"github.com/wallyqs/dhall.go/core"
+	"github.com/wallyqs/dhall.go/imports"
 	"github.com/wallyqs/dhall.go/term"
 
 	. "github.com/onsi/ginkgo"
@@ -20,6 +26,14 @@ func DecodeAndCompare(input core.Value, ptr interface{}, expected interface{}) {
 		To(Equal(expected))
 }
 
+func bigInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad big.Int literal " + s)
+	}
+	return i
+}
+
 func UnmarshalAndCompare(input string, ptr interface{}, expected interface{}) {
 	err := Unmarshal([]byte(input), ptr)
 	Expect(err).ToNot(HaveOccurred())
@@ -57,6 +71,79 @@ var _ = Describe("Decode", func() {
 		Entry("unmarshals TextLit into string",
 			core.PlainTextLit("lalala"), new(string), "lalala"),
 	)
+	DescribeTable("Temporal types", DecodeAndCompare,
+		Entry("unmarshals DateLit into Date",
+			core.DateLit{2021, 3, 14}, new(Date), Date{2021, time.March, 14}),
+		Entry("unmarshals DateLit into time.Time",
+			core.DateLit{2021, 3, 14}, new(time.Time),
+			time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)),
+		Entry("unmarshals TimeLit into TimeOfDay",
+			core.TimeLit{9, 30, 15, "25"}, new(TimeOfDay), TimeOfDay{9, 30, 15, 250000000}),
+		Entry("unmarshals TimeZoneLit into *time.Location",
+			core.TimeZoneLit(0), new(*time.Location), time.UTC),
+		Entry("unmarshals date, time and zone record into time.Time",
+			core.RecordLit{
+				"date":     core.DateLit{2021, 3, 14},
+				"time":     core.TimeLit{9, 30, 0, ""},
+				"timeZone": core.TimeZoneLit(0),
+			},
+			new(time.Time),
+			time.Date(2021, time.March, 14, 9, 30, 0, 0, time.UTC)),
+		Entry("unmarshals struct containing a time.Time",
+			core.RecordLit{
+				"Expiry": core.RecordLit{
+					"date": core.DateLit{2021, 3, 14},
+					"time": core.TimeLit{0, 0, 0, ""},
+				},
+			},
+			new(struct{ Expiry time.Time }),
+			struct{ Expiry time.Time }{time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)}),
+	)
+	DescribeTable("Bytes", DecodeAndCompare,
+		Entry("unmarshals BytesLit into []byte",
+			core.BytesLit{1, 2, 3}, new([]byte), []byte{1, 2, 3}),
+		Entry("unmarshals BytesLit into [N]byte",
+			core.BytesLit{1, 2, 3}, new([3]byte), [3]byte{1, 2, 3}),
+		Entry("unmarshals BytesLit into interface{}",
+			core.BytesLit{1, 2, 3}, new(interface{}), []byte{1, 2, 3}),
+	)
+	It("fails to unmarshal BytesLit into an array of the wrong length", func() {
+		var b [2]byte
+		Expect(Decode(core.BytesLit{1, 2, 3}, &b)).ToNot(Succeed())
+	})
+	DescribeTable("Big numbers", DecodeAndCompare,
+		Entry("unmarshals BigNaturalLit into *big.Int",
+			core.BigNaturalLit{bigInt("18446744073709551616")}, new(*big.Int),
+			bigInt("18446744073709551616")),
+		Entry("unmarshals BigIntegerLit into big.Int",
+			core.BigIntegerLit{bigInt("-18446744073709551616")}, new(big.Int),
+			*bigInt("-18446744073709551616")),
+		Entry("unmarshals NaturalLit into *big.Int",
+			core.NaturalLit(5), new(*big.Int), big.NewInt(5)),
+		Entry("unmarshals BigIntegerLit into interface{}",
+			core.BigIntegerLit{bigInt("18446744073709551616")}, new(interface{}),
+			bigInt("18446744073709551616")),
+		Entry("unmarshals NaturalLit beyond int64 into uint64",
+			core.NaturalLit(math.MaxUint64), new(uint64), uint64(math.MaxUint64)),
+	)
+	DescribeTable("Integer overflow",
+		func(input core.Value, ptr interface{}) {
+			Expect(Decode(input, ptr)).To(MatchError(ContainSubstring("overflows")))
+		},
+		Entry("NaturalLit into uint8", core.NaturalLit(256), new(uint8)),
+		Entry("NaturalLit into int64", core.NaturalLit(math.MaxUint64), new(int64)),
+		Entry("IntegerLit into int8", core.IntegerLit(-129), new(int8)),
+		Entry("BigNaturalLit into uint64",
+			core.BigNaturalLit{bigInt("18446744073709551616")}, new(uint64)),
+		Entry("BigIntegerLit into int",
+			core.BigIntegerLit{bigInt("-18446744073709551616")}, new(int)),
+	)
+	It("unmarshals a TimeZoneLit into a fixed zone", func() {
+		var loc *time.Location
+		Expect(Decode(core.TimeZoneLit(-330), &loc)).To(Succeed())
+		_, offset := time.Date(2021, time.March, 14, 0, 0, 0, 0, loc).Zone()
+		Expect(offset).To(Equal(-330 * 60))
+	})
 	DescribeTable("Compound types", DecodeAndCompare,
 		Entry("unmarshals Some 5 into int",
 			core.Some{core.NaturalLit(5)},
@@ -179,8 +266,37 @@ var _ = Describe("Decode", func() {
 				term.RecordType{"mapKey": term.Text, "mapValue": term.Natural}),
 			map[string]uint{"foo": 1, "bar": 2},
 		),
+		Entry("Date into Date", term.Date, Date{2021, time.March, 14}),
+		Entry("Time into TimeOfDay", term.Time, TimeOfDay{23, 59, 59, 500000000}),
+		Entry("Bytes into []byte", term.Bytes, []byte{0xde, 0xad}),
+		Entry("Bytes into [N]byte", term.Bytes, [2]byte{0xbe, 0xef}),
+		Entry("Natural into *big.Int", term.Natural, bigInt("18446744073709551616")),
+		Entry("Integer into *big.Int", term.Integer, bigInt("-18446744073709551617")),
 	)
 	Describe("Function types", func() {
+		id := func(typ term.Term) core.Value {
+			return core.Eval(term.Lambda{Label: "x", Type: typ, Body: term.NewVar("x")})
+		}
+		It("encodes a fixed *time.Location as a TimeZone", func() {
+			var fn func(*time.Location) *time.Location
+			Expect(Decode(id(term.TimeZone), &fn)).To(Succeed())
+
+			_, offset := time.Now().In(fn(time.FixedZone("", -330*60))).Zone()
+			Expect(offset).To(Equal(-330 * 60))
+		})
+		It("refuses to encode a *time.Location whose offset is not fixed", func() {
+			london, err := time.LoadLocation("Europe/London")
+			if err != nil {
+				Skip("no time zone database")
+			}
+			var fn func(*time.Location) *time.Location
+			Expect(Decode(id(term.TimeZone), &fn)).To(Succeed())
+
+			defer func() {
+				Expect(recover()).To(MatchError(ContainSubstring("offset from UTC is not fixed")))
+			}()
+			fn(london)
+		})
 		It("Decodes the Natural successor function", func() {
 			var fn func(uint) uint
 			dhallFn := core.Eval(term.Lambda{
@@ -306,6 +422,11 @@ var _ = Describe("Unmarshal", func() {
 		err := Unmarshal([]byte("1 + -2"), &actual)
 		Expect(err).To(HaveOccurred())
 	})
+	It("Reports where a type error occurred", func() {
+		var actual uint
+		err := Unmarshal([]byte("let x = 1\nin  x + True"), &actual)
+		Expect(err).To(MatchError(HavePrefix("-:2:5: ")))
+	})
 	It("Fetches imports", func() {
 		type Config struct {
 			Port int
@@ -316,6 +437,70 @@ var _ = Describe("Unmarshal", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(actual).To(Equal(Config{Port: 5050, Name: "inetd"}))
 	})
+	It("Stops when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		var actual int
+		err := UnmarshalContext(ctx, []byte("Natural/fold 100000000000 Integer Integer/negate +1"), &actual)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+	It("Fails when a limit is exceeded", func() {
+		var actual []int
+		err := UnmarshalWithLimits(context.Background(), []byte("[1, 2] # [3]"), core.Limits{MaxListLength: 2}, &actual)
+		Expect(err).To(Equal(&core.LimitError{Limit: core.ListLengthLimit, Max: 2}))
+		err = UnmarshalWithLimits(context.Background(), []byte("[1, 2] # [3]"), core.Limits{MaxListLength: 3}, &actual)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal([]int{1, 2, 3}))
+	})
+	It("Unmarshals a file with a given Loader", func() {
+		type Config struct {
+			Port int
+			Name string
+		}
+		var actual Config
+		err := UnmarshalFileWithLoader(context.Background(), imports.NewLoader(), "testdata/unmarshal-test.dhall", &actual)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(Equal(Config{Port: 5050, Name: "inetd"}))
+		loader := imports.NewLoader(imports.WithLimits(core.Limits{MaxDepth: 1}))
+		err = UnmarshalFileWithLoader(context.Background(), loader, "testdata/unmarshal-test.dhall", &actual)
+		Expect(err).To(Equal(&core.LimitError{Limit: core.DepthLimit, Max: 1}))
+	})
+	Describe("UnmarshalFS", func() {
+		type Config struct {
+			Port int
+			Name string
+		}
+		fsys := fstest.MapFS{
+			"app.dhall":         {Data: []byte(`./conf/server.dhall // { Name = "app" }`)},
+			"conf/server.dhall": {Data: []byte(`{ Port = ./port.dhall, Name = ../name.txt as Text }`)},
+			"conf/port.dhall":   {Data: []byte(`5050`)},
+			"name.txt":          {Data: []byte(`inetd`)},
+			"escape.dhall":      {Data: []byte(`../outside.dhall`)},
+			"conf/nested.dhall": {Data: []byte(`{ Port = 1, Name = ../../name.txt as Text }`)},
+		}
+		It("Resolves relative imports within the FS", func() {
+			var actual Config
+			err := UnmarshalFS(fsys, "conf/server.dhall", &actual)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(Config{Port: 5050, Name: "inetd"}))
+		})
+		It("Chains imports through several directories", func() {
+			var actual Config
+			err := UnmarshalFS(fsys, "app.dhall", &actual)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(Config{Port: 5050, Name: "app"}))
+		})
+		It("Fails if the file does not exist", func() {
+			var actual Config
+			err := UnmarshalFS(fsys, "missing.dhall", &actual)
+			Expect(err).To(HaveOccurred())
+		})
+		It("Does not escape the root of the FS", func() {
+			var actual Config
+			Expect(UnmarshalFS(fsys, "escape.dhall", &actual)).ToNot(Succeed())
+			Expect(UnmarshalFS(fsys, "conf/nested.dhall", &actual)).ToNot(Succeed())
+		})
+	})
 	Context("Unmarshalling functions", func() {
 		DescribeTable("Expected successes",
 			func(source string, targetVar interface{}, testInput interface{}, expectedOutput interface{}) {